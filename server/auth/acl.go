@@ -0,0 +1,127 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/helmutkemper/recode/server/middleware"
+	"gopkg.in/yaml.v3"
+)
+
+// ACL maps a route prefix to the scopes a Principal must hold to access it.
+// A prefix ending in "/" matches every path under it (e.g. "/git/" matches
+// "/git/clone/start"); any other entry must match the path exactly.
+//
+// Português:
+// ACL mapeia um prefixo de rota para os escopos que um Principal precisa ter
+// para acessá-la. Um prefixo terminado em "/" combina com qualquer caminho
+// abaixo dele (ex.: "/git/" combina com "/git/clone/start"); qualquer outra
+// entrada precisa combinar exatamente com o caminho.
+type ACL struct {
+	Routes map[string][]string `yaml:"routes"`
+}
+
+// DefaultACL matches the server's baseline policy: mutating routes require
+// the "write" scope, everything else (notably "/" and "/healthz") is public.
+//
+// Português:
+// DefaultACL reflete a política padrão do servidor: rotas de mutação exigem
+// o escopo "write", o resto (notadamente "/" e "/healthz") é público.
+func DefaultACL() ACL {
+	return ACL{Routes: map[string][]string{
+		"/ingest":    {"write"},
+		"/git/":      {"write"},
+		"/nr/flows":  {"write"}, // only reached for POST; GET is wired outside the protected chain
+		"/nr/flow/":  {"write"}, // only reached for PUT; GET is wired outside the protected chain
+		"/nr/nodes":  {"write"},
+		"/nr/nodes/": {"write"}, // covers DELETE /nr/nodes/{module}
+	}}
+}
+
+// LoadACL builds an ACL from ACL_CONFIG (inline YAML) or ACL_CONFIG_PATH (a
+// YAML file), falling back to DefaultACL when neither is set.
+//
+// Português:
+// LoadACL monta uma ACL a partir de ACL_CONFIG (YAML inline) ou
+// ACL_CONFIG_PATH (um arquivo YAML), recorrendo a DefaultACL quando nenhuma
+// das duas está definida.
+func LoadACL() (ACL, error) {
+	if inline := os.Getenv("ACL_CONFIG"); inline != "" {
+		var acl ACL
+		if err := yaml.Unmarshal([]byte(inline), &acl); err != nil {
+			return ACL{}, fmt.Errorf("auth: parsing ACL_CONFIG: %w", err)
+		}
+		return acl, nil
+	}
+	if p := os.Getenv("ACL_CONFIG_PATH"); p != "" {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return ACL{}, fmt.Errorf("auth: reading %s: %w", p, err)
+		}
+		var acl ACL
+		if err := yaml.Unmarshal(data, &acl); err != nil {
+			return ACL{}, fmt.Errorf("auth: parsing %s: %w", p, err)
+		}
+		return acl, nil
+	}
+	return DefaultACL(), nil
+}
+
+// scopesFor returns the scopes required for route, preferring an exact match
+// and falling back to the longest matching "/"-suffixed prefix.
+//
+// Português:
+// scopesFor retorna os escopos exigidos para route, preferindo uma
+// correspondência exata e recorrendo ao prefixo terminado em "/" mais longo
+// que combine.
+func (a ACL) scopesFor(route string) ([]string, bool) {
+	if scopes, ok := a.Routes[route]; ok {
+		return scopes, true
+	}
+	var best string
+	var bestScopes []string
+	for prefix, scopes := range a.Routes {
+		if strings.HasSuffix(prefix, "/") && strings.HasPrefix(route, prefix) && len(prefix) > len(best) {
+			best, bestScopes = prefix, scopes
+		}
+	}
+	if best == "" {
+		return nil, false
+	}
+	return bestScopes, true
+}
+
+// RequireScopes returns a middleware that looks up acl's required scopes
+// for the request path and, if any are set, rejects requests whose
+// Principal (populated upstream by BasicAuth/BearerAuth) doesn't hold every
+// one of them. A path with no ACL entry passes through unchanged.
+//
+// Português:
+// RequireScopes retorna um middleware que consulta os escopos exigidos por
+// acl para o caminho da requisição e, se houver algum, rejeita requisições
+// cujo Principal (populado a montante por BasicAuth/BearerAuth) não possua
+// todos eles. Um caminho sem entrada na ACL passa sem alteração.
+func RequireScopes(acl ACL) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		scopes, ok := acl.scopesFor(c.Request.URL.Path)
+		if !ok || len(scopes) == 0 {
+			c.Next()
+			return
+		}
+
+		principal, ok := PrincipalFromContext(c)
+		if !ok {
+			c.AbortWithJSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		for _, s := range scopes {
+			if !principal.HasScope(s) {
+				c.AbortWithJSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+				return
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,33 @@
+package auth
+
+import (
+	"log"
+
+	"github.com/helmutkemper/recode/server/middleware"
+)
+
+// Audit returns a middleware that logs one audit entry per request for a
+// named mutation (e.g. "ingest", "git.start", "nodered.deploy"), recording
+// who performed it. It must run after BasicAuth/BearerAuth so a Principal is
+// already on the context; requests without one are logged as "anonymous"
+// rather than skipped, so a misconfigured ACL doesn't leave mutations
+// unaudited.
+//
+// Português:
+// Audit retorna um middleware que loga uma entrada de auditoria por
+// requisição para uma mutação nomeada (ex.: "ingest", "git.start",
+// "nodered.deploy"), registrando quem a realizou. Deve rodar depois de
+// BasicAuth/BearerAuth para que um Principal já esteja no contexto;
+// requisições sem um são logadas como "anonymous" em vez de ignoradas, para
+// que uma ACL mal configurada não deixe mutações sem auditoria.
+func Audit(action string) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		c.Next()
+
+		subject := "anonymous"
+		if principal, ok := PrincipalFromContext(c); ok {
+			subject = principal.Subject
+		}
+		log.Printf("audit: action=%s subject=%s remote=%s path=%s", action, subject, c.Request.RemoteAddr, c.Request.URL.Path)
+	}
+}
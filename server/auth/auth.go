@@ -0,0 +1,186 @@
+// Package auth
+//
+// English:
+//
+//	Authentication/authorization for the HTTP server: BasicAuth and
+//	BearerAuth middlewares populate a Principal on the request context,
+//	constant-time credential comparison avoids timing side channels, and a
+//	WWW-Authenticate challenge is issued on failure. Route-level scope
+//	enforcement (the "which routes need which scopes" part) lives in acl.go;
+//	audit logging of authenticated mutations lives in audit.go.
+//
+// Português:
+//
+//	Autenticação/autorização para o servidor HTTP: os middlewares BasicAuth
+//	e BearerAuth populam um Principal no contexto da requisição, a
+//	comparação de credenciais em tempo constante evita canais laterais de
+//	tempo, e um desafio WWW-Authenticate é emitido em caso de falha. A
+//	aplicação de escopos por rota ("quais rotas exigem quais escopos") mora
+//	em acl.go; o log de auditoria de mutações autenticadas mora em audit.go.
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/helmutkemper/recode/server/middleware"
+)
+
+// Principal identifies whoever authenticated a request, and what they're
+// allowed to do.
+//
+// Português:
+// Principal identifica quem autenticou a requisição, e o que tem permissão
+// para fazer.
+type Principal struct {
+	Subject string
+	Scopes  []string
+}
+
+// HasScope reports whether p was granted scope.
+//
+// Português:
+// HasScope informa se p recebeu o escopo scope.
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// principalKey is the middleware.Context key BasicAuth/BearerAuth store the
+// authenticated Principal under.
+//
+// Português:
+// principalKey é a chave do middleware.Context sob a qual BasicAuth/BearerAuth
+// armazenam o Principal autenticado.
+const principalKey = "auth.principal"
+
+// PrincipalFromContext returns the Principal populated by BasicAuth or
+// BearerAuth, if the request passed through one of them.
+//
+// Português:
+// PrincipalFromContext retorna o Principal populado por BasicAuth ou
+// BearerAuth, se a requisição passou por um deles.
+func PrincipalFromContext(c *middleware.Context) (Principal, bool) {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return Principal{}, false
+	}
+	p, ok := v.(Principal)
+	return p, ok
+}
+
+// Account is one entry of an Accounts table: the password to compare
+// against and the scopes granted once authenticated.
+//
+// Português:
+// Account é uma entrada de uma tabela Accounts: a senha a comparar e os
+// escopos concedidos uma vez autenticado.
+type Account struct {
+	Password string
+	Scopes   []string
+}
+
+// Accounts is a username -> Account table consulted by BasicAuth.
+//
+// Português:
+// Accounts é uma tabela usuário -> Account consultada pelo BasicAuth.
+type Accounts map[string]Account
+
+// authenticate checks user/pass against a, in constant time with respect to
+// both the username (every entry is compared, not just a map lookup) and
+// the password, so a failed login doesn't leak which part was wrong or
+// whether the username even exists.
+//
+// Português:
+// authenticate verifica user/pass contra a, em tempo constante tanto em
+// relação ao usuário (toda entrada é comparada, não apenas um lookup no
+// mapa) quanto à senha, de forma que um login falho não vaze qual parte
+// estava errada nem se o usuário sequer existe.
+func (a Accounts) authenticate(user, pass string) (Principal, bool) {
+	var account Account
+	found := 0
+	for u, acc := range a {
+		if subtle.ConstantTimeCompare([]byte(u), []byte(user)) == 1 {
+			account = acc
+			found = 1
+		}
+	}
+	if subtle.ConstantTimeCompare([]byte(account.Password), []byte(pass)) != 1 || found != 1 {
+		return Principal{}, false
+	}
+	return Principal{Subject: user, Scopes: account.Scopes}, true
+}
+
+// challenge aborts the request with 401 and a WWW-Authenticate header
+// naming scheme (e.g. "Basic" or "Bearer") and realm.
+//
+// Português:
+// challenge aborta a requisição com 401 e um cabeçalho WWW-Authenticate
+// nomeando o esquema (ex.: "Basic" ou "Bearer") e o realm.
+func challenge(c *middleware.Context, scheme, realm string) {
+	if realm == "" {
+		realm = "recode"
+	}
+	c.Writer.Header().Set("WWW-Authenticate", fmt.Sprintf("%s realm=%q", scheme, realm))
+	c.AbortWithJSON(http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+}
+
+// BasicAuth returns a middleware that requires HTTP Basic credentials
+// matching an entry in accounts, storing the resulting Principal on the
+// context. realm is sent back in the WWW-Authenticate challenge on failure.
+//
+// Português:
+// BasicAuth retorna um middleware que exige credenciais HTTP Basic que
+// correspondam a uma entrada em accounts, armazenando o Principal resultante
+// no contexto. realm é devolvido no desafio WWW-Authenticate em caso de falha.
+func BasicAuth(accounts Accounts, realm string) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		user, pass, ok := c.Request.BasicAuth()
+		if !ok {
+			challenge(c, "Basic", realm)
+			return
+		}
+		principal, ok := accounts.authenticate(user, pass)
+		if !ok {
+			challenge(c, "Basic", realm)
+			return
+		}
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// BearerAuth returns a middleware that requires an "Authorization: Bearer
+// <token>" header accepted by validator, storing the resulting Principal on
+// the context. realm is sent back in the WWW-Authenticate challenge on
+// failure.
+//
+// Português:
+// BearerAuth retorna um middleware que exige um cabeçalho
+// "Authorization: Bearer <token>" aceito por validator, armazenando o
+// Principal resultante no contexto. realm é devolvido no desafio
+// WWW-Authenticate em caso de falha.
+func BearerAuth(validator func(token string) (Principal, bool), realm string) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		authz := c.Request.Header.Get("Authorization")
+		const prefix = "Bearer "
+		if !strings.HasPrefix(authz, prefix) {
+			challenge(c, "Bearer", realm)
+			return
+		}
+		token := strings.TrimPrefix(authz, prefix)
+		principal, ok := validator(token)
+		if !ok {
+			challenge(c, "Bearer", realm)
+			return
+		}
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
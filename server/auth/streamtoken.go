@@ -0,0 +1,111 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// streamTokenSecret is the HMAC key used to sign stream tokens. It is read
+// once from STREAM_TOKEN_SECRET, or generated per process if that's unset;
+// a per-process secret is fine here because stream tokens are short-lived
+// and only ever need to be verified by the process that issued them.
+//
+// Português:
+// streamTokenSecret é a chave HMAC usada para assinar stream tokens. É lida
+// uma vez de STREAM_TOKEN_SECRET, ou gerada por processo se essa variável
+// não estiver definida; um segredo por processo é suficiente aqui porque
+// stream tokens têm vida curta e só precisam ser verificados pelo mesmo
+// processo que os emitiu.
+var (
+	streamSecretOnce sync.Once
+	streamSecret     []byte
+)
+
+func streamTokenSecret() []byte {
+	streamSecretOnce.Do(func() {
+		if s := os.Getenv("STREAM_TOKEN_SECRET"); s != "" {
+			streamSecret = []byte(s)
+			return
+		}
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			panic(fmt.Sprintf("auth: generating stream token secret: %v", err))
+		}
+		streamSecret = buf
+	})
+	return streamSecret
+}
+
+// IssueStreamToken returns a short-lived, HMAC-signed token scoping access
+// to nodeID for ttl. It exists because EventSource/WebSocket clients can't
+// send an Authorization header, so the read-only /git/clone/stream|ws/{id}
+// routes accept this token on the query string instead of a full
+// BasicAuth/BearerAuth credential, while still requiring that the caller
+// first authenticated to start the clone (see handleGitStart).
+//
+// Português:
+// IssueStreamToken retorna um token de vida curta, assinado com HMAC, que
+// restringe o acesso a nodeID por ttl. Existe porque clientes
+// EventSource/WebSocket não conseguem enviar um cabeçalho Authorization,
+// então as rotas somente-leitura /git/clone/stream|ws/{id} aceitam esse
+// token na query string em vez de uma credencial completa de
+// BasicAuth/BearerAuth, exigindo ainda assim que o chamador tenha se
+// autenticado antes para iniciar o clone (veja handleGitStart).
+func IssueStreamToken(nodeID string, ttl time.Duration) string {
+	exp := time.Now().Add(ttl).Unix()
+	payload := nodeID + "." + strconv.FormatInt(exp, 10)
+	return payload + "." + sign(payload)
+}
+
+// ValidateStreamToken reports whether token was issued by IssueStreamToken
+// for nodeID and hasn't expired yet.
+//
+// Português:
+// ValidateStreamToken informa se token foi emitido por IssueStreamToken
+// para nodeID e ainda não expirou.
+func ValidateStreamToken(nodeID, token string) bool {
+	// Split from the right: exp and sig never contain '.', nodeID may.
+	i := strings.LastIndex(token, ".")
+	if i < 0 {
+		return false
+	}
+	j := strings.LastIndex(token[:i], ".")
+	if j < 0 {
+		return false
+	}
+	tokenNode, expStr, sig := token[:j], token[j+1:i], token[i+1:]
+
+	if subtle.ConstantTimeCompare([]byte(tokenNode), []byte(nodeID)) != 1 {
+		return false
+	}
+
+	payload := tokenNode + "." + expStr
+	if subtle.ConstantTimeCompare([]byte(sig), []byte(sign(payload))) != 1 {
+		return false
+	}
+
+	exp, err := strconv.ParseInt(expStr, 10, 64)
+	if err != nil {
+		return false
+	}
+	return time.Now().Unix() <= exp
+}
+
+// sign returns the hex-encoded HMAC-SHA256 of payload under streamTokenSecret.
+//
+// Português:
+// sign retorna o HMAC-SHA256 de payload, codificado em hex, sob streamTokenSecret.
+func sign(payload string) string {
+	mac := hmac.New(sha256.New, streamTokenSecret())
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
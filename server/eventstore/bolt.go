@@ -0,0 +1,169 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// eventsBucket is the single bbolt bucket used to key events by id.
+//
+// Português:
+// eventsBucket é o único bucket do bbolt usado para indexar eventos por id.
+var eventsBucket = []byte("events")
+
+// hashBucket indexes event ids by content Hash, so Add can spot a
+// duplicate ingest without scanning eventsBucket.
+//
+// Português:
+// hashBucket indexa ids de evento pelo Hash de conteúdo, para que Add
+// detecte um ingest duplicado sem varrer eventsBucket.
+var hashBucket = []byte("events_by_hash")
+
+// BoltStore persists events to a single bbolt file, giving durability
+// across restarts without running a separate database process.
+//
+// Português:
+// BoltStore persiste eventos em um único arquivo bbolt, dando durabilidade
+// entre reinícios sem precisar rodar um processo de banco separado.
+type BoltStore struct {
+	db *bolt.DB
+
+	subMu sync.Mutex
+	subs  map[chan Event]struct{}
+}
+
+// OpenBoltStore opens (creating if needed) the bbolt file at path.
+//
+// Português:
+// OpenBoltStore abre (criando se necessário) o arquivo bbolt em path.
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0o600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open bbolt: %w", err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(eventsBucket); err != nil {
+			return err
+		}
+		_, err := tx.CreateBucketIfNotExists(hashBucket)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("create bucket: %w", err)
+	}
+	return &BoltStore{db: db, subs: make(map[chan Event]struct{})}, nil
+}
+
+// Close releases the underlying bbolt file.
+//
+// Português:
+// Close libera o arquivo bbolt subjacente.
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
+
+// Add persists ev, unless hashBucket already holds an id for ev.Hash, in
+// which case the duplicate is dropped and the already-stored event is
+// returned instead.
+//
+// Português:
+// Add persiste ev, a menos que hashBucket já tenha um id para ev.Hash,
+// caso em que a duplicata é descartada e o evento já armazenado é
+// retornado.
+func (s *BoltStore) Add(ev Event) (Event, error) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return Event{}, err
+	}
+	var existing Event
+	dup := false
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		hb := tx.Bucket(hashBucket)
+		if id := hb.Get([]byte(ev.Hash)); id != nil {
+			dup = true
+			v := tx.Bucket(eventsBucket).Get(id)
+			return json.Unmarshal(v, &existing)
+		}
+		if err := tx.Bucket(eventsBucket).Put([]byte(ev.ID), data); err != nil {
+			return err
+		}
+		return hb.Put([]byte(ev.Hash), []byte(ev.ID))
+	})
+	if err != nil {
+		return Event{}, err
+	}
+	if dup {
+		return existing, nil
+	}
+	s.publish(ev)
+	return ev, nil
+}
+
+func (s *BoltStore) List(filter Filter) ([]Event, error) {
+	var out []Event
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(eventsBucket).ForEach(func(_, v []byte) error {
+			var ev Event
+			if err := json.Unmarshal(v, &ev); err != nil {
+				return err
+			}
+			if matches(ev, filter) {
+				out = append(out, ev)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[len(out)-filter.Limit:]
+	}
+	return out, nil
+}
+
+func (s *BoltStore) Get(id string) (Event, error) {
+	var ev Event
+	err := s.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(eventsBucket).Get([]byte(id))
+		if v == nil {
+			return fmt.Errorf("event %q not found", id)
+		}
+		return json.Unmarshal(v, &ev)
+	})
+	return ev, err
+}
+
+func (s *BoltStore) Subscribe(ctx context.Context) <-chan Event {
+	ch := make(chan Event, 16)
+
+	s.subMu.Lock()
+	s.subs[ch] = struct{}{}
+	s.subMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.subMu.Lock()
+		delete(s.subs, ch)
+		s.subMu.Unlock()
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (s *BoltStore) publish(ev Event) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
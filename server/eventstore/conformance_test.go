@@ -0,0 +1,184 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newStoreFuncs enumerates every EventStore backend so the conformance
+// tests below run identically against all three; a backend that diverges
+// from this shared contract (as happened once with List ordering between
+// SQLiteStore and Memory/Bolt) fails here instead of only in production.
+//
+// Português:
+// newStoreFuncs enumera todos os backends de EventStore para que os testes
+// de conformidade abaixo rodem identicamente nos três; um backend que
+// diverge desse contrato compartilhado (como já aconteceu uma vez com a
+// ordenação de List entre SQLiteStore e Memory/Bolt) falha aqui em vez de
+// só em produção.
+func newStoreFuncs(t *testing.T) map[string]func() EventStore {
+	return map[string]func() EventStore{
+		"memory": func() EventStore {
+			return NewMemoryStore(100)
+		},
+		"bolt": func() EventStore {
+			s, err := OpenBoltStore(filepath.Join(t.TempDir(), "events.db"))
+			if err != nil {
+				t.Fatalf("open bolt store: %v", err)
+			}
+			t.Cleanup(func() { _ = s.Close() })
+			return s
+		},
+		"sqlite": func() EventStore {
+			s, err := OpenSQLiteStore(filepath.Join(t.TempDir(), "events.db"))
+			if err != nil {
+				t.Fatalf("open sqlite store: %v", err)
+			}
+			t.Cleanup(func() { _ = s.Close() })
+			return s
+		},
+	}
+}
+
+// newTestEvent builds an Event whose body embeds id, so two events with
+// the same typ still hash to distinct content and aren't deduped against
+// each other by Add.
+//
+// Português:
+// newTestEvent monta um Event cujo corpo embute id, de forma que dois
+// eventos com o mesmo typ ainda tenham hashes de conteúdo distintos e não
+// sejam deduplicados entre si por Add.
+func newTestEvent(id, typ string) Event {
+	body, _ := json.Marshal(map[string]string{"type": typ, "id": id})
+	return NewEvent(id, "127.0.0.1", body)
+}
+
+func TestEventStoreConformance(t *testing.T) {
+	for name, newStore := range newStoreFuncs(t) {
+		t.Run(name, func(t *testing.T) {
+			store := newStore()
+
+			ev1 := newTestEvent("1", "a")
+			time.Sleep(time.Millisecond)
+			ev2 := newTestEvent("2", "b")
+			time.Sleep(time.Millisecond)
+			ev3 := newTestEvent("3", "a")
+
+			for _, ev := range []Event{ev1, ev2, ev3} {
+				if _, err := store.Add(ev); err != nil {
+					t.Fatalf("Add(%s): %v", ev.ID, err)
+				}
+			}
+
+			t.Run("dedupe returns the stored event", func(t *testing.T) {
+				// Same body as ev1 (so the same content hash), different id,
+				// simulating a caller retrying an ingest it already sent.
+				dup := NewEvent("1-retry", "127.0.0.1", ev1.Body)
+				got, err := store.Add(dup)
+				if err != nil {
+					t.Fatalf("Add(dup): %v", err)
+				}
+				if got.ID != ev1.ID {
+					t.Fatalf("Add(dup) = id %q, want the original %q", got.ID, ev1.ID)
+				}
+			})
+
+			t.Run("Get returns the stored event", func(t *testing.T) {
+				got, err := store.Get(ev2.ID)
+				if err != nil {
+					t.Fatalf("Get(%s): %v", ev2.ID, err)
+				}
+				if got.ID != ev2.ID {
+					t.Fatalf("Get(%s) = id %q", ev2.ID, got.ID)
+				}
+				if _, err := store.Get("missing"); err == nil {
+					t.Fatal("Get(missing) = nil error, want one")
+				}
+			})
+
+			t.Run("List returns events oldest-first", func(t *testing.T) {
+				out, err := store.List(Filter{})
+				if err != nil {
+					t.Fatalf("List: %v", err)
+				}
+				if len(out) != 3 {
+					t.Fatalf("List returned %d events, want 3", len(out))
+				}
+				if out[0].ID != ev1.ID || out[1].ID != ev2.ID || out[2].ID != ev3.ID {
+					t.Fatalf("List order = %s,%s,%s, want %s,%s,%s", out[0].ID, out[1].ID, out[2].ID, ev1.ID, ev2.ID, ev3.ID)
+				}
+			})
+
+			t.Run("List Limit keeps the newest matches", func(t *testing.T) {
+				out, err := store.List(Filter{Limit: 2})
+				if err != nil {
+					t.Fatalf("List(Limit: 2): %v", err)
+				}
+				if len(out) != 2 {
+					t.Fatalf("List(Limit: 2) returned %d events, want 2", len(out))
+				}
+				if out[0].ID != ev2.ID || out[1].ID != ev3.ID {
+					t.Fatalf("List(Limit: 2) = %s,%s, want %s,%s", out[0].ID, out[1].ID, ev2.ID, ev3.ID)
+				}
+			})
+
+			t.Run("List Since excludes events at or before it", func(t *testing.T) {
+				out, err := store.List(Filter{Since: ev1.At})
+				if err != nil {
+					t.Fatalf("List(Since: ev1.At): %v", err)
+				}
+				if len(out) != 2 {
+					t.Fatalf("List(Since: ev1.At) returned %d events, want 2", len(out))
+				}
+				if out[0].ID != ev2.ID || out[1].ID != ev3.ID {
+					t.Fatalf("List(Since: ev1.At) = %s,%s, want %s,%s", out[0].ID, out[1].ID, ev2.ID, ev3.ID)
+				}
+			})
+
+			t.Run("List TypePath/TypeEquals filters on Body", func(t *testing.T) {
+				out, err := store.List(Filter{TypePath: "type", TypeEquals: "a"})
+				if err != nil {
+					t.Fatalf("List(type=a): %v", err)
+				}
+				if len(out) != 2 {
+					t.Fatalf("List(type=a) returned %d events, want 2", len(out))
+				}
+				if out[0].ID != ev1.ID || out[1].ID != ev3.ID {
+					t.Fatalf("List(type=a) = %s,%s, want %s,%s", out[0].ID, out[1].ID, ev1.ID, ev3.ID)
+				}
+			})
+
+			t.Run("Subscribe is fed new Adds and closes with ctx", func(t *testing.T) {
+				ctx, cancel := context.WithCancel(context.Background())
+				ch := store.Subscribe(ctx)
+
+				ev4 := newTestEvent("4", "c")
+				if _, err := store.Add(ev4); err != nil {
+					t.Fatalf("Add(ev4): %v", err)
+				}
+
+				select {
+				case got := <-ch:
+					if got.ID != ev4.ID {
+						t.Fatalf("Subscribe delivered id %q, want %q", got.ID, ev4.ID)
+					}
+				case <-time.After(time.Second):
+					t.Fatal("Subscribe: timed out waiting for the new event")
+				}
+
+				cancel()
+				select {
+				case _, ok := <-ch:
+					if ok {
+						t.Fatal("Subscribe channel delivered an unexpected value instead of closing")
+					}
+				case <-time.After(time.Second):
+					t.Fatal("Subscribe: channel did not close after ctx was cancelled")
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package eventstore
+//
+// English:
+//
+//	Defines the storage contract for ingested events and ships three
+//	implementations: an in-memory ring (the original behaviour), a BoltDB
+//	store for durable single-file persistence, and a SQLite store for
+//	queryable persistence without CGO. Callers depend on the EventStore
+//	interface so the backend can be swapped via configuration.
+//
+// Português:
+//
+//	Define o contrato de armazenamento para eventos ingeridos e traz três
+//	implementações: um anel em memória (comportamento original), um store
+//	BoltDB para persistência durável em arquivo único, e um store SQLite
+//	para persistência consultável sem CGO. Os chamadores dependem da
+//	interface EventStore para que o backend possa ser trocado por
+//	configuração.
+package eventstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Event represents one stored JSON event.
+//
+// Português:
+// Event representa um evento JSON armazenado.
+type Event struct {
+	ID         string          `json:"id"`
+	At         time.Time       `json:"at"`
+	RemoteAddr string          `json:"remoteAddr"`
+	Body       json.RawMessage `json:"body"`
+
+	// Hash is a content hash of Body. Add implementations key on it to
+	// silently drop a duplicate ingest instead of storing it twice.
+	//
+	// Português:
+	// Hash é um hash do conteúdo de Body. As implementações de Add usam-no
+	// como chave para descartar silenciosamente um ingest duplicado em vez
+	// de armazená-lo duas vezes.
+	Hash string `json:"hash"`
+}
+
+// NewEvent builds an Event, filling in ID, At and Hash from body.
+//
+// Português:
+// NewEvent monta um Event, preenchendo ID, At e Hash a partir de body.
+func NewEvent(id string, remoteAddr string, body json.RawMessage) Event {
+	return Event{
+		ID:         id,
+		At:         time.Now(),
+		RemoteAddr: remoteAddr,
+		Body:       body,
+		Hash:       contentHash(body),
+	}
+}
+
+// contentHash returns the hex-encoded SHA-256 of body, used for dedupe.
+//
+// Português:
+// contentHash retorna o SHA-256 de body em hexadecimal, usado para dedupe.
+func contentHash(body json.RawMessage) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// Filter narrows down a List query.
+//
+// Português:
+// Filter restringe uma consulta List.
+type Filter struct {
+	// Since only returns events strictly after this time. Zero means no lower bound.
+	//
+	// Português:
+	// Since retorna apenas eventos estritamente após este horário. Zero significa sem limite inferior.
+	Since time.Time
+
+	// Limit caps the number of returned events. Zero/negative means no cap.
+	//
+	// Português:
+	// Limit limita a quantidade de eventos retornados. Zero/negativo significa sem limite.
+	Limit int
+
+	// TypePath is a dot-separated JSON path (e.g. "payload.type") evaluated
+	// against Body; when TypeEquals is also set, only events whose value at
+	// TypePath equals TypeEquals are returned.
+	//
+	// Português:
+	// TypePath é um caminho JSON separado por pontos (ex.: "payload.type")
+	// avaliado contra Body; quando TypeEquals também está definido, apenas
+	// eventos cujo valor em TypePath seja igual a TypeEquals são retornados.
+	TypePath   string
+	TypeEquals string
+}
+
+// EventStore is the storage contract every backend implements.
+//
+// Português:
+// EventStore é o contrato de armazenamento que todo backend implementa.
+type EventStore interface {
+	// Add persists ev and returns the Event actually held in the store
+	// under ev.Hash: ev itself on a fresh insert, or the event that was
+	// already stored with that hash on a duplicate ingest (in which case ev
+	// is not persisted again).
+	//
+	// Português:
+	// Add persiste ev e retorna o Event efetivamente guardado no store sob
+	// ev.Hash: o próprio ev em uma inserção nova, ou o evento já armazenado
+	// com esse hash em caso de ingest duplicado (nesse caso ev não é
+	// persistido novamente).
+	Add(ev Event) (Event, error)
+
+	// List returns events matching filter, newest restrictions applied
+	// server-side (Since/Limit/Type).
+	//
+	// Português:
+	// List retorna eventos que casam com filter, com as restrições
+	// aplicadas no servidor (Since/Limit/Type).
+	List(filter Filter) ([]Event, error)
+
+	// Get returns the event with the given id.
+	//
+	// Português:
+	// Get retorna o evento com o id informado.
+	Get(id string) (Event, error)
+
+	// Subscribe returns a channel fed with every Event added after the
+	// call. The channel is closed when ctx is done.
+	//
+	// Português:
+	// Subscribe retorna um canal alimentado com cada Event adicionado após
+	// a chamada. O canal é fechado quando ctx termina.
+	Subscribe(ctx context.Context) <-chan Event
+}
+
+// matches reports whether ev satisfies filter.
+//
+// Português:
+// matches informa se ev satisfaz filter.
+func matches(ev Event, filter Filter) bool {
+	if !filter.Since.IsZero() && !ev.At.After(filter.Since) {
+		return false
+	}
+	if filter.TypePath != "" && filter.TypeEquals != "" {
+		v, ok := jsonPathString(ev.Body, filter.TypePath)
+		if !ok || v != filter.TypeEquals {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonPathString walks a dot-separated path into a JSON document and
+// returns the string value found there, if any.
+//
+// Português:
+// jsonPathString percorre um caminho separado por pontos dentro de um
+// documento JSON e retorna o valor string encontrado ali, se houver.
+func jsonPathString(body json.RawMessage, dotPath string) (string, bool) {
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return "", false
+	}
+	cur := doc
+	for _, key := range splitPath(dotPath) {
+		m, ok := cur.(map[string]any)
+		if !ok {
+			return "", false
+		}
+		cur, ok = m[key]
+		if !ok {
+			return "", false
+		}
+	}
+	s, ok := cur.(string)
+	return s, ok
+}
+
+func splitPath(p string) []string {
+	var out []string
+	start := 0
+	for i := 0; i < len(p); i++ {
+		if p[i] == '.' {
+			out = append(out, p[start:i])
+			start = i + 1
+		}
+	}
+	out = append(out, p[start:])
+	return out
+}
@@ -0,0 +1,119 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// MemoryStore is a ring buffer EventStore, the original in-process
+// behaviour. It does not survive restarts.
+//
+// Português:
+// MemoryStore é um EventStore em anel, o comportamento original em
+// processo. Não sobrevive a reinícios.
+type MemoryStore struct {
+	mu     sync.Mutex
+	events []Event
+	max    int
+
+	subMu sync.Mutex
+	subs  map[chan Event]struct{}
+}
+
+// NewMemoryStore creates a MemoryStore holding at most max events.
+//
+// Português:
+// NewMemoryStore cria um MemoryStore guardando no máximo max eventos.
+func NewMemoryStore(max int) *MemoryStore {
+	return &MemoryStore{events: make([]Event, 0, max), max: max, subs: make(map[chan Event]struct{})}
+}
+
+// Add persists ev, unless an event with the same Hash is still in the
+// window, in which case the duplicate is dropped and the already-stored
+// event is returned instead.
+//
+// Português:
+// Add persiste ev, a menos que um evento com o mesmo Hash ainda esteja na
+// janela, caso em que a duplicata é descartada e o evento já armazenado é
+// retornado.
+func (s *MemoryStore) Add(ev Event) (Event, error) {
+	s.mu.Lock()
+	for _, existing := range s.events {
+		if existing.Hash == ev.Hash {
+			s.mu.Unlock()
+			return existing, nil
+		}
+	}
+	if len(s.events) == s.max {
+		copy(s.events, s.events[1:])
+		s.events[len(s.events)-1] = ev
+	} else {
+		s.events = append(s.events, ev)
+	}
+	s.mu.Unlock()
+
+	s.publish(ev)
+	return ev, nil
+}
+
+func (s *MemoryStore) List(filter Filter) ([]Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]Event, 0, len(s.events))
+	for _, ev := range s.events {
+		if matches(ev, filter) {
+			out = append(out, ev)
+		}
+	}
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[len(out)-filter.Limit:]
+	}
+	return out, nil
+}
+
+func (s *MemoryStore) Get(id string) (Event, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ev := range s.events {
+		if ev.ID == id {
+			return ev, nil
+		}
+	}
+	return Event{}, fmt.Errorf("event %q not found", id)
+}
+
+func (s *MemoryStore) Subscribe(ctx context.Context) <-chan Event {
+	ch := make(chan Event, 16)
+
+	s.subMu.Lock()
+	s.subs[ch] = struct{}{}
+	s.subMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.subMu.Lock()
+		delete(s.subs, ch)
+		s.subMu.Unlock()
+		close(ch)
+	}()
+
+	return ch
+}
+
+// publish fans ev out to every subscriber without blocking on a slow one.
+//
+// Português:
+// publish distribui ev para cada assinante sem bloquear por causa de um lento.
+func (s *MemoryStore) publish(ev Event) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- ev:
+		default:
+			// slow subscriber: drop rather than block ingestion.
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package eventstore
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite" // CGO-free driver registered as "sqlite"
+)
+
+// SQLiteStore persists events to a SQLite file with an indexed `at` column
+// so List can push Since filtering down to the database instead of
+// scanning every row; Limit is applied afterward, in Go, once the
+// TypePath/TypeEquals filter has narrowed the result set.
+//
+// Português:
+// SQLiteStore persiste eventos em um arquivo SQLite com uma coluna `at`
+// indexada, de forma que List empurra a filtragem por Since para o banco
+// em vez de varrer todas as linhas; Limit é aplicado depois, em Go, assim
+// que o filtro TypePath/TypeEquals reduziu o conjunto de resultados.
+type SQLiteStore struct {
+	db *sql.DB
+
+	subMu sync.Mutex
+	subs  map[chan Event]struct{}
+}
+
+// OpenSQLiteStore opens (creating if needed) the SQLite file at path and
+// ensures the events table and its `at` index exist.
+//
+// Português:
+// OpenSQLiteStore abre (criando se necessário) o arquivo SQLite em path e
+// garante que a tabela events e seu índice em `at` existam.
+func OpenSQLiteStore(path string) (*SQLiteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("open sqlite: %w", err)
+	}
+
+	const schema = `
+CREATE TABLE IF NOT EXISTS events (
+	id          TEXT PRIMARY KEY,
+	at          DATETIME NOT NULL,
+	remote_addr TEXT NOT NULL,
+	body        TEXT NOT NULL,
+	hash        TEXT NOT NULL UNIQUE
+);
+CREATE INDEX IF NOT EXISTS idx_events_at ON events(at);
+`
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("migrate sqlite schema: %w", err)
+	}
+
+	return &SQLiteStore{db: db, subs: make(map[chan Event]struct{})}, nil
+}
+
+// Close releases the underlying SQLite connection.
+//
+// Português:
+// Close libera a conexão SQLite subjacente.
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// Add persists ev, unless the `hash` UNIQUE constraint rejects it as a
+// duplicate ingest, in which case it's dropped and the already-stored event
+// is returned instead.
+//
+// Português:
+// Add persiste ev, a menos que a constraint UNIQUE de `hash` a rejeite como
+// ingest duplicado, caso em que é descartada e o evento já armazenado é
+// retornado.
+func (s *SQLiteStore) Add(ev Event) (Event, error) {
+	res, err := s.db.Exec(
+		`INSERT OR IGNORE INTO events (id, at, remote_addr, body, hash) VALUES (?, ?, ?, ?, ?)`,
+		ev.ID, ev.At, ev.RemoteAddr, string(ev.Body), ev.Hash,
+	)
+	if err != nil {
+		return Event{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return Event{}, err
+	} else if n == 0 {
+		existing, err := s.getByHash(ev.Hash)
+		if err != nil {
+			return Event{}, err
+		}
+		return existing, nil
+	}
+	s.publish(ev)
+	return ev, nil
+}
+
+// getByHash returns the event stored under the given content hash.
+//
+// Português:
+// getByHash retorna o evento armazenado sob o hash de conteúdo informado.
+func (s *SQLiteStore) getByHash(hash string) (Event, error) {
+	var (
+		ev   Event
+		body string
+	)
+	row := s.db.QueryRow(`SELECT id, at, remote_addr, body, hash FROM events WHERE hash = ?`, hash)
+	if err := row.Scan(&ev.ID, &ev.At, &ev.RemoteAddr, &body, &ev.Hash); err != nil {
+		return Event{}, err
+	}
+	ev.Body = json.RawMessage(body)
+	return ev, nil
+}
+
+// List applies Since and the TypePath/TypeEquals filter before Limit, then
+// keeps the newest Limit matches (mirroring MemoryStore and BoltStore,
+// which both trim their already-chronological slice from the end). Limit
+// can't be pushed down to SQL because the type filter runs in Go, so the
+// query fetches every row newer than Since in ascending order and the
+// trimming happens after filtering.
+//
+// Português:
+// List aplica Since e o filtro TypePath/TypeEquals antes do Limit, e então
+// mantém os Limit matches mais recentes (espelhando MemoryStore e
+// BoltStore, que cortam do fim a fatia já cronológica). Limit não pode ser
+// empurrado para o SQL porque o filtro de tipo roda em Go, então a query
+// busca todas as linhas mais novas que Since em ordem ascendente e o corte
+// acontece depois da filtragem.
+func (s *SQLiteStore) List(filter Filter) ([]Event, error) {
+	since := filter.Since
+	if since.IsZero() {
+		since = time.Unix(0, 0)
+	}
+
+	rows, err := s.db.Query(
+		`SELECT id, at, remote_addr, body, hash FROM events WHERE at > ? ORDER BY at ASC`,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []Event
+	for rows.Next() {
+		var (
+			ev   Event
+			body string
+		)
+		if err := rows.Scan(&ev.ID, &ev.At, &ev.RemoteAddr, &body, &ev.Hash); err != nil {
+			return nil, err
+		}
+		ev.Body = json.RawMessage(body)
+		if filter.TypePath != "" && filter.TypeEquals != "" {
+			if v, ok := jsonPathString(ev.Body, filter.TypePath); !ok || v != filter.TypeEquals {
+				continue
+			}
+		}
+		out = append(out, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[len(out)-filter.Limit:]
+	}
+	return out, nil
+}
+
+func (s *SQLiteStore) Get(id string) (Event, error) {
+	var (
+		ev   Event
+		body string
+	)
+	row := s.db.QueryRow(`SELECT id, at, remote_addr, body, hash FROM events WHERE id = ?`, id)
+	if err := row.Scan(&ev.ID, &ev.At, &ev.RemoteAddr, &body, &ev.Hash); err != nil {
+		if err == sql.ErrNoRows {
+			return Event{}, fmt.Errorf("event %q not found", id)
+		}
+		return Event{}, err
+	}
+	ev.Body = json.RawMessage(body)
+	return ev, nil
+}
+
+func (s *SQLiteStore) Subscribe(ctx context.Context) <-chan Event {
+	ch := make(chan Event, 16)
+
+	s.subMu.Lock()
+	s.subs[ch] = struct{}{}
+	s.subMu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		s.subMu.Lock()
+		delete(s.subs, ch)
+		s.subMu.Unlock()
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (s *SQLiteStore) publish(ev Event) {
+	s.subMu.Lock()
+	defer s.subMu.Unlock()
+	for ch := range s.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package loghub
+
+import "time"
+
+// Deadline implements a resettable deadline the same way the stdlib's
+// net.Pipe does: a timer arms a cancel channel that callers select on, and
+// resetting the deadline swaps in a fresh channel so nobody can observe a
+// stale cancellation from a previous deadline. Exported so transport
+// adapters outside this package (SSE, WebSocket, ...) can build their own
+// read-side deadlines on top of the same primitive the Client uses for
+// writes.
+//
+// Português:
+// Deadline implementa um deadline reiniciável da mesma forma que o
+// net.Pipe da stdlib: um timer arma um canal de cancelamento no qual os
+// chamadores fazem select, e reiniciar o deadline troca para um canal novo,
+// de forma que ninguém observe um cancelamento obsoleto de um deadline
+// anterior. Exportado para que adaptadores de transporte fora deste pacote
+// (SSE, WebSocket, ...) construam seus próprios deadlines de leitura sobre
+// a mesma primitiva que o Client usa para escritas.
+type Deadline struct {
+	mu     chan struct{} // 1-buffered mutex
+	timer  *time.Timer
+	cancel chan struct{}
+}
+
+// NewDeadline returns a Deadline with no expiry armed.
+//
+// Português:
+// NewDeadline retorna um Deadline sem expiração armada.
+func NewDeadline() Deadline {
+	d := Deadline{mu: make(chan struct{}, 1), cancel: make(chan struct{})}
+	d.mu <- struct{}{}
+	return d
+}
+
+// Set arms the deadline for t. A zero t disarms it (waits forever).
+//
+// Português:
+// Set arma o deadline para t. Um t zero o desarma (espera para sempre).
+func (d *Deadline) Set(t time.Time) {
+	<-d.mu
+	defer func() { d.mu <- struct{}{} }()
+
+	if d.timer != nil && !d.timer.Stop() {
+		<-d.cancel // drain: timer already fired and closed the old channel
+	}
+	d.timer = nil
+
+	select {
+	case <-d.cancel:
+		d.cancel = make(chan struct{})
+	default:
+	}
+
+	if t.IsZero() {
+		return
+	}
+	if dur := time.Until(t); dur <= 0 {
+		close(d.cancel)
+	} else {
+		cancel := d.cancel
+		d.timer = time.AfterFunc(dur, func() { close(cancel) })
+	}
+}
+
+// Wait returns the channel that closes once the current deadline expires.
+//
+// Português:
+// Wait retorna o canal que fecha quando o deadline atual expira.
+func (d *Deadline) Wait() chan struct{} {
+	<-d.mu
+	defer func() { d.mu <- struct{}{} }()
+	return d.cancel
+}
@@ -0,0 +1,275 @@
+// Package loghub
+//
+// English:
+//
+//	Transport-agnostic hub that fans out Msg values to the clients
+//	subscribed to a channel id, with per-client backpressure and
+//	read/write deadlines. It grew out of an SSE-only hub; a Transport now
+//	captures the one thing that differs between SSE and WebSocket (how a
+//	single frame is written onto the wire), so the same Hub/Client serve
+//	both.
+//
+// Português:
+//
+//	Hub agnóstico de transporte que distribui valores Msg para os clientes
+//	assinantes de um id de canal, com backpressure e deadlines de
+//	leitura/escrita por cliente. Nasceu de um hub só de SSE; um Transport
+//	agora captura a única coisa que difere entre SSE e WebSocket (como um
+//	único frame é escrito no fio), de forma que o mesmo Hub/Client sirva
+//	ambos.
+package loghub
+
+import (
+	"sync"
+	"time"
+)
+
+// outboxSize bounds how many pending messages a client can accumulate
+// before the hub starts applying the write deadline.
+//
+// Português:
+// outboxSize limita quantas mensagens pendentes um cliente pode acumular
+// antes do hub começar a aplicar o deadline de escrita.
+const outboxSize = 256
+
+// Msg is a single frame of application payload. Event is transport-defined
+// (SSE uses it as the `event:` field; WebSocket adapters may ignore it);
+// Data is marshalled to JSON unless it is already []byte/string.
+//
+// Português:
+// Msg é um único frame de payload de aplicação. Event é definido pelo
+// transporte (SSE o usa como campo `event:`; adaptadores WebSocket podem
+// ignorá-lo); Data é serializado para JSON a menos que já seja []byte/string.
+type Msg struct {
+	Event string
+	Data  any
+}
+
+// Transport writes one Msg onto whatever wire a concrete adapter owns
+// (an SSE http.ResponseWriter, a WebSocket connection, ...).
+//
+// Português:
+// Transport escreve um Msg no fio que um adaptador concreto possuir (um
+// http.ResponseWriter de SSE, uma conexão WebSocket, ...).
+type Transport interface {
+	WriteFrame(msg Msg) error
+}
+
+// Client wraps a Transport with a bounded outbox, a dedicated writer
+// goroutine, and read/write deadlines, so a slow or wedged connection can
+// never block the hub or its other subscribers.
+//
+// Português:
+// Client envolve um Transport com uma caixa de saída limitada, uma
+// goroutine de escrita dedicada, e deadlines de leitura/escrita, de forma
+// que uma conexão lenta ou travada nunca bloqueie o hub ou seus outros assinantes.
+type Client struct {
+	transport Transport
+
+	outbox chan Msg
+	closed chan struct{}
+	once   sync.Once
+
+	ReadDeadline  Deadline
+	WriteDeadline Deadline
+}
+
+// NewClient wraps transport in a Client ready to be registered on a Hub.
+//
+// Português:
+// NewClient envolve transport em um Client pronto para ser registrado em um Hub.
+func NewClient(transport Transport) *Client {
+	return &Client{
+		transport:     transport,
+		outbox:        make(chan Msg, outboxSize),
+		closed:        make(chan struct{}),
+		ReadDeadline:  NewDeadline(),
+		WriteDeadline: NewDeadline(),
+	}
+}
+
+// Close stops the client's writer goroutine and marks it dead. Safe to
+// call more than once.
+//
+// Português:
+// Close interrompe a goroutine de escrita do cliente e o marca como morto.
+// Seguro para chamar mais de uma vez.
+func (c *Client) Close() {
+	c.once.Do(func() { close(c.closed) })
+}
+
+// Done reports the channel that closes once the client is closed, so
+// adapters can select on it alongside their own transport-specific events.
+//
+// Português:
+// Done informa o canal que fecha quando o cliente é fechado, para que
+// adaptadores façam select nele junto com seus próprios eventos específicos de transporte.
+func (c *Client) Done() <-chan struct{} { return c.closed }
+
+// Send enqueues msg, waiting at most until the write deadline expires if
+// the outbox is full. Returns false if the deadline won or the client is
+// closed, in which case the caller should disconnect it.
+//
+// Português:
+// Send enfileira msg, esperando no máximo até o deadline de escrita expirar
+// se a caixa de saída estiver cheia. Retorna false se o deadline venceu ou
+// o cliente está fechado; nesse caso o chamador deve desconectá-lo.
+func (c *Client) Send(msg Msg) bool {
+	select {
+	case c.outbox <- msg:
+		return true
+	case <-c.closed:
+		return false
+	default:
+	}
+
+	select {
+	case c.outbox <- msg:
+		return true
+	case <-c.WriteDeadline.Wait():
+		return false
+	case <-c.closed:
+		return false
+	}
+}
+
+// RunWriter drains the outbox onto the transport until Close is called or
+// a write fails. Callers run it in its own goroutine.
+//
+// Português:
+// RunWriter drena a caixa de saída para o transporte até Close ser chamado
+// ou uma escrita falhar. Os chamadores o rodam em sua própria goroutine.
+func (c *Client) RunWriter() {
+	for {
+		select {
+		case msg := <-c.outbox:
+			if err := c.transport.WriteFrame(msg); err != nil {
+				c.Close()
+				return
+			}
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+// Hub fans out messages to the clients subscribed to each channel id.
+//
+// Português:
+// Hub distribui mensagens para os clientes assinantes de cada id de canal.
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[string]map[*Client]struct{}
+
+	cfgMu         sync.RWMutex
+	writeDeadline time.Duration
+	idleTimeout   time.Duration
+}
+
+// NewHub creates a Hub with sane defaults: a 5s write deadline and a 60s
+// idle timeout (adapters typically ping at idleTimeout/2).
+//
+// Português:
+// NewHub cria um Hub com padrões razoáveis: deadline de escrita de 5s e
+// timeout de ociosidade de 60s (adaptadores tipicamente fazem ping a cada idleTimeout/2).
+func NewHub() *Hub {
+	return &Hub{
+		clients:       make(map[string]map[*Client]struct{}),
+		writeDeadline: 5 * time.Second,
+		idleTimeout:   60 * time.Second,
+	}
+}
+
+// SetWriteDeadline changes how long a send waits on a full client outbox
+// before dropping the client.
+//
+// Português:
+// SetWriteDeadline muda quanto tempo um envio espera por uma caixa de saída
+// cheia antes de descartar o cliente.
+func (h *Hub) SetWriteDeadline(d time.Duration) {
+	h.cfgMu.Lock()
+	h.writeDeadline = d
+	h.cfgMu.Unlock()
+}
+
+// SetIdleTimeout changes the keepalive interval derived for new clients.
+//
+// Português:
+// SetIdleTimeout muda o intervalo de keepalive derivado para novos clientes.
+func (h *Hub) SetIdleTimeout(d time.Duration) {
+	h.cfgMu.Lock()
+	h.idleTimeout = d
+	h.cfgMu.Unlock()
+}
+
+// Settings returns the hub's current write deadline and idle timeout, for
+// adapters to derive their own ping cadence from.
+//
+// Português:
+// Settings retorna o deadline de escrita e o timeout de ociosidade atuais
+// do hub, para que adaptadores derivem sua própria cadência de ping.
+func (h *Hub) Settings() (writeDeadline, idleTimeout time.Duration) {
+	h.cfgMu.RLock()
+	defer h.cfgMu.RUnlock()
+	return h.writeDeadline, h.idleTimeout
+}
+
+// Add registers c under channel id.
+//
+// Português:
+// Add registra c sob o canal id.
+func (h *Hub) Add(id string, c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.clients[id] == nil {
+		h.clients[id] = make(map[*Client]struct{})
+	}
+	h.clients[id][c] = struct{}{}
+}
+
+// Remove unregisters c from channel id.
+//
+// Português:
+// Remove desregistra c do canal id.
+func (h *Hub) Remove(id string, c *Client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if set := h.clients[id]; set != nil {
+		delete(set, c)
+		if len(set) == 0 {
+			delete(h.clients, id)
+		}
+	}
+}
+
+// Broadcast sends msg to every client subscribed to id. A client whose
+// outbox stays full past the write deadline is dropped instead of stalling
+// the rest of the channel's subscribers. Each client's deadline-wait runs
+// on its own goroutine so one stalled subscriber can't delay delivery to
+// the others.
+//
+// Português:
+// Broadcast envia msg para cada cliente assinante de id. Um cliente cuja
+// caixa de saída permaneça cheia além do deadline de escrita é descartado
+// em vez de travar o restante dos assinantes do canal. A espera do deadline
+// de cada cliente roda em sua própria goroutine, para que um assinante
+// travado não atrase a entrega aos demais.
+func (h *Hub) Broadcast(id string, msg Msg) {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients[id]))
+	for c := range h.clients[id] {
+		clients = append(clients, c)
+	}
+	h.mu.RUnlock()
+
+	writeDeadline, _ := h.Settings()
+	for _, c := range clients {
+		c.WriteDeadline.Set(time.Now().Add(writeDeadline))
+		go func(c *Client) {
+			if !c.Send(msg) {
+				c.Close()
+				h.Remove(id, c)
+			}
+		}(c)
+	}
+}
@@ -4,31 +4,71 @@
 //   Minimal HTTP server to receive and display user-generated JSON data,
 //   and to visualize the user's Node-RED diagram (nodes+wires) on a canvas.
 //   Endpoints:
-//     - POST /ingest     : accept arbitrary JSON; stores it in memory
-//     - GET  /events     : returns last events as JSON
+//     - POST /ingest     : accept arbitrary JSON; stores it via the pluggable EventStore
+//     - GET  /events     : returns stored events as JSON (?since=, ?limit=, ?type=)
+//     - GET  /events/stream : SSE stream pushing newly ingested events in real time
 //     - GET  /           : HTML page: send/view data + flow viewer canvas
-//     - GET  /nr/flows   : proxy Node-RED flows JSON (from http://node-red:1880/flows)
+//     - GET/POST /nr/flows     : read or deploy the full Node-RED flow set
+//     - GET/PUT  /nr/flow/{id} : read or replace a single flow (tab)
+//     - GET/POST /nr/nodes     : list or install Node-RED node modules
+//     - DELETE   /nr/nodes/{m} : remove an installed node module
+//     - GET  /nr/settings : Node-RED runtime settings
 //     - GET  /healthz    : liveness probe
+//     - POST /git/clone/start        : clones a repo with go-git, streaming progress over SSE
+//     - GET  /git/clone/stream/{id}  : SSE stream of clone progress (one-way), requires ?token=
+//     - GET  /git/clone/ws/{id}      : WebSocket stream of clone progress, also accepting
+//                                      inbound control frames ({"cmd":"cancel"|"pause"|"resume"|"input"}),
+//                                      requires ?token=
+//     - POST /git/clone/cancel/{id}  : cancels the in-flight clone for a nodeId
 //
 //   Design notes:
 //   - Single-responsibility, self-contained handlers.
-//   - No external deps; all stdlib.
-//   - CORS enabled for simple integration.
+//   - Real cloning is delegated to the gitclone package (go-git); the rest stays stdlib.
+//   - Cross-cutting concerns (CORS, method checks, logging, recovery) live in the
+//     middleware package and are composed per-route with a Chain, not re-implemented
+//     in each handler.
+//   - /ingest, write-side /git/* (start/cancel) and write-side /nr/* require HTTP
+//     Basic credentials (the auth package) and are audited; /, /healthz and
+//     read-side /nr/* stay public. /git/clone/stream|ws/{id} can't sit behind Basic
+//     auth since EventSource/WebSocket clients can't send that header, but they
+//     aren't public either: handleGitStart hands back a short-lived, nodeId-scoped
+//     stream token (auth.IssueStreamToken) that the caller must pass as ?token=
+//     to open either stream.
 //
 // Português:
 //   Servidor HTTP minimalista para receber e exibir dados JSON e
 //   visualizar o diagrama do Node-RED (nós+fios) em um canvas.
 //   Endpoints:
-//     - POST /ingest     : recebe JSON arbitrário; guarda em memória
-//     - GET  /events     : retorna eventos em JSON
+//     - POST /ingest     : recebe JSON arbitrário; guarda via EventStore plugável
+//     - GET  /events     : retorna eventos armazenados em JSON (?since=, ?limit=, ?type=)
+//     - GET  /events/stream : stream SSE que envia eventos recém-ingeridos em tempo real
 //     - GET  /           : página HTML (envio/visualização + viewer do flow)
-//     - GET  /nr/flows   : proxy do JSON de flows do Node-RED
+//     - GET/POST /nr/flows     : lê ou faz deploy do conjunto completo de flows
+//     - GET/PUT  /nr/flow/{id} : lê ou substitui um único flow (tab)
+//     - GET/POST /nr/nodes     : lista ou instala módulos de node do Node-RED
+//     - DELETE   /nr/nodes/{m} : remove um módulo de node instalado
+//     - GET  /nr/settings : configurações de runtime do Node-RED
 //     - GET  /healthz    : verificador de vida
+//     - POST /git/clone/start        : clona um repo com go-git, transmitindo progresso via SSE
+//     - GET  /git/clone/stream/{id}  : stream SSE do progresso do clone (apenas um sentido), exige ?token=
+//     - GET  /git/clone/ws/{id}      : stream WebSocket do progresso do clone, também aceitando
+//                                      frames de controle de entrada ({"cmd":"cancel"|"pause"|"resume"|"input"}),
+//                                      exige ?token=
+//     - POST /git/clone/cancel/{id}  : cancela o clone em andamento do nodeId
 //
 //   Notas de projeto:
 //   - Handlers simples e auto contidos.
-//   - Sem dependências externas; apenas stdlib.
-//   - CORS habilitado para integração simples.
+//   - O clone real é delegado ao pacote gitclone (go-git); o resto continua stdlib.
+//   - Questões transversais (CORS, checagem de método, log, recuperação de panic) moram
+//     no pacote middleware e são compostas por rota com uma Chain, sem reimplementação
+//     em cada handler.
+//   - /ingest, o lado de escrita de /git/* (start/cancel) e o lado de escrita de
+//     /nr/* exigem credenciais HTTP Basic (pacote auth) e são auditados; /, /healthz
+//     e o lado de leitura de /nr/* continuam públicos. /git/clone/stream|ws/{id} não
+//     pode ficar atrás de Basic auth porque clientes EventSource/WebSocket não
+//     conseguem enviar esse cabeçalho, mas também não são públicos: handleGitStart
+//     devolve um stream token de vida curta, restrito ao nodeId (auth.IssueStreamToken),
+//     que o chamador precisa passar como ?token= para abrir qualquer um dos streams.
 
 package main
 
@@ -44,9 +84,18 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strconv"
 	"strings"
-	"sync"
 	"time"
+
+	"github.com/helmutkemper/recode/server/auth"
+	"github.com/helmutkemper/recode/server/eventstore"
+	"github.com/helmutkemper/recode/server/gitclone"
+	"github.com/helmutkemper/recode/server/loghub"
+	"github.com/helmutkemper/recode/server/middleware"
+	"github.com/helmutkemper/recode/server/nodered"
+	"github.com/helmutkemper/recode/server/sse"
+	"github.com/helmutkemper/recode/server/wsgit"
 )
 
 // initRuleBook
@@ -65,63 +114,24 @@ import (
 
 // ------------------------- SSE payload -------------------------
 
+// sseMsg is the JSON shape every channel on the hub speaks; the transport
+// concerns (backpressure and deadlines in the loghub package, framing in
+// its sse and wsgit adapters) live elsewhere, this is just application
+// payload.
+//
+// Português:
+// sseMsg é a forma JSON que todo canal do hub fala; as questões de
+// transporte (backpressure e deadlines no pacote loghub, enquadramento nos
+// adaptadores sse e wsgit) moram em outro lugar, isto é apenas o payload
+// da aplicação.
 type sseMsg struct {
-	Type   string `json:"type"`             // "hello" | "log" | "done"
+	Type   string `json:"type"`             // "log" | "done" | "event"
 	Stream string `json:"stream,omitempty"` // "stdout" | "stderr"
 	Line   string `json:"line,omitempty"`
-	Code   int    `json:"code,omitempty"`
-	Target string `json:"target,omitempty"`
-}
-
-// ------------------------- SSE hub ----------------------------
-
-type sseClient struct {
-	w  http.ResponseWriter
-	fl http.Flusher
-}
-
-type sseHub struct {
-	mu      sync.RWMutex
-	clients map[string]map[*sseClient]struct{} // nodeId -> set
-}
-
-func newSSEHub() *sseHub {
-	return &sseHub{clients: make(map[string]map[*sseClient]struct{})}
-}
-
-func (h *sseHub) add(id string, c *sseClient) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	if h.clients[id] == nil {
-		h.clients[id] = make(map[*sseClient]struct{})
-	}
-	h.clients[id][c] = struct{}{}
-}
-
-func (h *sseHub) remove(id string, c *sseClient) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-	if set := h.clients[id]; set != nil {
-		delete(set, c)
-		if len(set) == 0 {
-			delete(h.clients, id)
-		}
-	}
-}
-
-func (h *sseHub) broadcast(id string, msg sseMsg) {
-	h.mu.RLock()
-	set := h.clients[id]
-	h.mu.RUnlock()
-	if len(set) == 0 {
-		return
-	}
-	data, _ := json.Marshal(msg)
-	for c := range set {
-		c.w.Header().Set("Access-Control-Allow-Origin", "*") // CORS simples
-		_, _ = c.w.Write([]byte("data: " + string(data) + "\n\n"))
-		c.fl.Flush()
-	}
+	Code       int    `json:"code,omitempty"`
+	Target     string `json:"target,omitempty"`
+	SHA        string `json:"sha,omitempty"`        // resolved commit SHA; only set on "done"
+	DurationMs int64  `json:"durationMs,omitempty"` // clone wall time; only set on "done"
 }
 
 // ------------------------- LiveLog io.Writer ------------------
@@ -129,7 +139,7 @@ func (h *sseHub) broadcast(id string, msg sseMsg) {
 // sseWriter converte writes arbitrários em linhas no SSE.
 // Ele acumula fragmentos que não terminam com '\n' até completar uma linha.
 type sseWriter struct {
-	h      *sseHub
+	h      *loghub.Hub
 	id     string
 	stream string
 	buf    []byte
@@ -144,7 +154,7 @@ func (w *sseWriter) Write(p []byte) (int, error) {
 	for sc.Scan() {
 		line := sc.Text()
 		used += len(line) + 1 // + '\n' (se não houver, ajustamos abaixo)
-		w.h.broadcast(w.id, sseMsg{Type: "log", Stream: w.stream, Line: line + "\n"})
+		w.h.Broadcast(w.id, loghub.Msg{Data: sseMsg{Type: "log", Stream: w.stream, Line: line + "\n"}})
 	}
 	// Se o último fragmento não tinha '\n', o 'used' vai avançar 1 além do tamanho real.
 	if used > len(w.buf) {
@@ -154,56 +164,92 @@ func (w *sseWriter) Write(p []byte) (int, error) {
 	return len(p), nil
 }
 
-func LiveLogWriter(h *sseHub, nodeID, stream string) *sseWriter {
+func LiveLogWriter(h *loghub.Hub, nodeID, stream string) *sseWriter {
 	return &sseWriter{h: h, id: nodeID, stream: stream}
 }
 
+// streamTokenTTL bounds how long a token handed out by handleGitStart can
+// be used to open a clone's log stream.
+//
+// Português:
+// streamTokenTTL limita por quanto tempo um token devolvido por
+// handleGitStart pode ser usado para abrir o stream de log de um clone.
+const streamTokenTTL = 10 * time.Minute
+
+// requireStreamToken validates the ?token= query parameter against nodeID,
+// aborting the request with 401 if it's missing, malformed or expired. It
+// exists because handleGitStream/handleGitWS sit on routes the browser
+// reaches via EventSource/WebSocket, neither of which can send an
+// Authorization header, so they can't sit behind the regular
+// BasicAuth/BearerAuth chain the way /git/clone/start does.
+//
+// Português:
+// requireStreamToken valida o parâmetro de query ?token= contra nodeID,
+// abortando a requisição com 401 se estiver ausente, malformado ou
+// expirado. Existe porque handleGitStream/handleGitWS ficam em rotas que o
+// browser acessa via EventSource/WebSocket, nenhum dos quais consegue
+// enviar um cabeçalho Authorization, então não podem ficar atrás da cadeia
+// normal de BasicAuth/BearerAuth da forma que /git/clone/start fica.
+func requireStreamToken(c *middleware.Context, nodeID string) bool {
+	if auth.ValidateStreamToken(nodeID, c.Request.URL.Query().Get("token")) {
+		return true
+	}
+	c.AbortWithJSON(http.StatusUnauthorized, map[string]string{"error": "missing or invalid stream token"})
+	return false
+}
+
 // GET /git/clone/stream/{id}  -> abre SSE
-func handleGitStream(h *sseHub) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
-		if r.Method != http.MethodGet {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+func handleGitStream(h *loghub.Hub) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		nodeID := strings.TrimPrefix(c.Request.URL.Path, "/git/clone/stream/")
+		nodeID = path.Clean("/" + nodeID)[1:] // sanitiza. IMPORTANTE: precisa existir id
+		if !requireStreamToken(c, nodeID) {
 			return
 		}
+		_ = sse.Open(h, nodeID, c.Writer, c.Request)
+	}
+}
 
-		nodeID := strings.TrimPrefix(r.URL.Path, "/git/clone/stream/")
-		nodeID = path.Clean("/" + nodeID)[1:] // sanitiza. IMPORTANTE: precisa existir id
-
-		w.Header().Set("Content-Type", "text/event-stream")
-		w.Header().Set("Cache-Control", "no-cache")
-		w.Header().Set("Connection", "keep-alive")
-
-		fl, ok := w.(http.Flusher)
-		if !ok {
-			http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+// GET /git/clone/ws/{id}  -> abre WebSocket (bidirecional: aceita
+// {"cmd":"cancel"|"pause"|"resume"|"input"} vindos do browser)
+func handleGitWS(h *loghub.Hub, mgr *gitclone.Manager) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		nodeID := strings.TrimPrefix(c.Request.URL.Path, "/git/clone/ws/")
+		nodeID = path.Clean("/" + nodeID)[1:]
+		if !requireStreamToken(c, nodeID) {
 			return
 		}
+		_ = wsgit.Open(h, nodeID, c.Writer, c.Request, func() bool { return mgr.Cancel(nodeID) })
+	}
+}
 
-		c := &sseClient{w: w, fl: fl}
-		h.add(nodeID, c)
-
-		_, _ = w.Write([]byte("data: {\"type\":\"hello\"}\n\n"))
-		fl.Flush()
-
-		tick := time.NewTicker(25 * time.Second)
-		defer tick.Stop()
+// eventsChannelID is the fixed hub channel used to push newly ingested
+// events to subscribed browsers in real time.
+//
+// Português:
+// eventsChannelID é o canal fixo do hub usado para enviar eventos
+// recém-ingeridos a navegadores inscritos em tempo real.
+const eventsChannelID = "events"
+
+// GET /events/stream  -> abre SSE no canal de eventos ingeridos
+func handleEventsStream(h *loghub.Hub) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		_ = sse.Open(h, eventsChannelID, c.Writer, c.Request)
+	}
+}
 
-		ctx := r.Context()
-		for {
-			select {
-			case <-ctx.Done():
-				h.remove(nodeID, c)
-				return
-			case <-tick.C:
-				_, _ = w.Write([]byte("event: ping\ndata: {}\n\n"))
-				fl.Flush()
-			}
-		}
+// pumpEventStoreToHub forwards every event added to store into h's events
+// channel until ctx is done, so browsers watching /events/stream see new
+// ingests without polling.
+//
+// Português:
+// pumpEventStoreToHub encaminha cada evento adicionado a store para o canal
+// de eventos de h até ctx terminar, de forma que navegadores assistindo
+// /events/stream vejam novos ingests sem polling.
+func pumpEventStoreToHub(ctx context.Context, store eventstore.EventStore, h *loghub.Hub) {
+	for ev := range store.Subscribe(ctx) {
+		data, _ := json.Marshal(ev)
+		h.Broadcast(eventsChannelID, loghub.Msg{Data: sseMsg{Type: "event", Line: string(data)}})
 	}
 }
 
@@ -212,70 +258,135 @@ type startReq struct {
 	Repo   string `json:"repo"`
 	Branch string `json:"branch"`
 	Dest   string `json:"destDir"`
+
+	// Auth: at most one of the following is expected to be set.
+	//
+	// Português:
+	// Auth: no máximo um dos campos abaixo deve ser preenchido.
+	Username       string `json:"username,omitempty"`
+	Password       string `json:"password,omitempty"`
+	GitHubToken    string `json:"githubToken,omitempty"`
+	SSHKeyPEM      string `json:"sshKeyPem,omitempty"`
+	SSHKeyPassword string `json:"sshKeyPassword,omitempty"`
+}
+
+// auth builds a *gitclone.Auth from the request, or nil if no credentials
+// were provided.
+//
+// Português:
+// auth monta um *gitclone.Auth a partir da requisição, ou nil se nenhuma
+// credencial foi informada.
+func (in startReq) auth() *gitclone.Auth {
+	if in.Username == "" && in.Password == "" && in.GitHubToken == "" && in.SSHKeyPEM == "" {
+		return nil
+	}
+	return &gitclone.Auth{
+		Username:       in.Username,
+		Password:       in.Password,
+		GitHubToken:    in.GitHubToken,
+		SSHKeyPEM:      []byte(in.SSHKeyPEM),
+		SSHKeyPassword: in.SSHKeyPassword,
+	}
 }
 
-// POST /git/clone/start  -> simula "git clone" por ~2min e escreve em tempo real
-func handleGitStart(h *sseHub) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusNoContent)
+// cloneRoot confines every clone destination under a single base
+// directory, so a caller with the "write" scope can't point a clone at an
+// arbitrary server-writable path via destDir.
+//
+// Português:
+// cloneRoot confina todo destino de clone sob um único diretório base, de
+// forma que um chamador com o escopo "write" não consiga apontar um clone
+// para um caminho arbitrário gravável no servidor via destDir.
+var cloneRoot = path.Join(os.TempDir(), "recode-clone")
+
+// resolveCloneDest joins requested under cloneRoot, rejecting ".."
+// segments that would escape it; an absolute requested is not rejected but
+// taken as relative to cloneRoot. An empty requested falls back to nodeID,
+// matching the previous default.
+//
+// Português:
+// resolveCloneDest junta requested sob cloneRoot, rejeitando segmentos ".."
+// que escapariam dele; um requested absoluto não é rejeitado, mas tratado
+// como relativo a cloneRoot. Um requested vazio volta para nodeID,
+// preservando o padrão anterior.
+func resolveCloneDest(nodeID, requested string) (string, error) {
+	if requested == "" {
+		requested = nodeID
+	}
+	dest := path.Join(cloneRoot, requested)
+	if dest != cloneRoot && !strings.HasPrefix(dest, cloneRoot+"/") {
+		return "", fmt.Errorf("destDir escapes the clone root")
+	}
+	return dest, nil
+}
+
+// POST /git/clone/start  -> clona o repositório de verdade via go-git e
+// transmite o progresso em tempo real pelo SSE.
+func handleGitStart(h *loghub.Hub, mgr *gitclone.Manager) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		var in startReq
+		if err := json.NewDecoder(c.Request.Body).Decode(&in); err != nil {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": "bad json"})
 			return
 		}
-		if r.Method != http.MethodPost {
-			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		if in.NodeID == "" {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": "nodeId required"})
 			return
 		}
-
-		var in startReq
-		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
-			http.Error(w, "bad json", http.StatusBadRequest)
+		if in.Repo == "" {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": "repo required"})
 			return
 		}
-		if in.NodeID == "" {
-			http.Error(w, "nodeId required", http.StatusBadRequest)
+		dest, err := resolveCloneDest(in.NodeID, in.Dest)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 			return
 		}
-
-		// Goroutine que escreve stdout/stderr no SSE por ~2min.
-		go func(id string) {
-			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
-			defer cancel()
-
-			stdout := LiveLogWriter(h, id, "stdout")
-			stderr := LiveLogWriter(h, id, "stderr")
-
-			fmt.Fprintln(stdout, "starting clone...")
-			target := "/tmp/fake/" + id
-			t := time.NewTicker(900 * time.Millisecond)
-			defer t.Stop()
-
-			step := 0
-			for {
-				select {
-				case <-ctx.Done():
-					h.broadcast(id, sseMsg{Type: "done", Code: 0, Target: target, Line: "finished\n"})
-					return
-				case <-t.C:
-					step++
-					if step%4 == 0 {
-						fmt.Fprintln(stderr, "remote: counting objects...")
-					} else {
-						fmt.Fprintf(stdout, "Cloning into '%s'... step=%d\n", target, step)
-					}
-				}
+		in.Dest = dest
+
+		stdout := LiveLogWriter(h, in.NodeID, "stdout")
+		stderr := LiveLogWriter(h, in.NodeID, "stderr")
+		fmt.Fprintf(stdout, "starting clone of %s into %s...\n", in.Repo, in.Dest)
+
+		mgr.Start(context.Background(), gitclone.Options{
+			NodeID: in.NodeID,
+			Repo:   in.Repo,
+			Branch: in.Branch,
+			Dest:   in.Dest,
+			Auth:   in.auth(),
+		}, stdout, func(res gitclone.Result) {
+			line := "finished\n"
+			if res.Err != nil {
+				line = res.Err.Error() + "\n"
+				fmt.Fprint(stderr, line)
 			}
-		}(in.NodeID)
+			h.Broadcast(in.NodeID, loghub.Msg{Data: sseMsg{Type: "done", Code: res.Code, Target: res.Target, SHA: res.SHA, DurationMs: res.Duration.Milliseconds(), Line: line}})
+		})
 
-		w.Header().Set("Content-Type", "application/json")
-		_ = json.NewEncoder(w).Encode(map[string]any{
-			"started": true,
-			"pid":     12345,
-			"target":  "/tmp/fake/" + in.NodeID,
+		c.JSON(http.StatusOK, map[string]any{
+			"started":     true,
+			"target":      in.Dest,
+			"streamToken": auth.IssueStreamToken(in.NodeID, streamTokenTTL),
 		})
 	}
 }
 
+// POST or DELETE /git/clone/cancel/{id}  -> cancela o clone em andamento
+// para o nodeId.
+func handleGitCancel(mgr *gitclone.Manager) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		nodeID := strings.TrimPrefix(c.Request.URL.Path, "/git/clone/cancel/")
+		nodeID = path.Clean("/" + nodeID)[1:]
+		if nodeID == "" {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": "nodeId required"})
+			return
+		}
+
+		cancelled := mgr.Cancel(nodeID)
+		c.JSON(http.StatusOK, map[string]any{"cancelled": cancelled})
+	}
+}
+
 // initRuleBook
 //
 // English:
@@ -294,183 +405,260 @@ func handleGitStart(h *sseHub) http.HandlerFunc {
 //	Todas as funções devem ser simples
 func initRuleBook() {}
 
-// Event represents one stored JSON event.
+// openEventStore selects an eventstore.EventStore implementation based on
+// the EVENT_STORE_DRIVER env var ("memory", "bolt", "sqlite"), defaulting
+// to the in-memory ring.
 //
 // Português:
-// Event representa um evento JSON armazenado.
-type Event struct {
-	ID         string          `json:"id"`
-	At         time.Time       `json:"at"`
-	RemoteAddr string          `json:"remoteAddr"`
-	Body       json.RawMessage `json:"body"`
+// openEventStore seleciona uma implementação de eventstore.EventStore com
+// base na env var EVENT_STORE_DRIVER ("memory", "bolt", "sqlite"), usando
+// o anel em memória por padrão.
+func openEventStore() (eventstore.EventStore, error) {
+	switch os.Getenv("EVENT_STORE_DRIVER") {
+	case "bolt":
+		path := os.Getenv("EVENT_STORE_PATH")
+		if path == "" {
+			path = "events.bolt"
+		}
+		return eventstore.OpenBoltStore(path)
+	case "sqlite":
+		path := os.Getenv("EVENT_STORE_PATH")
+		if path == "" {
+			path = "events.sqlite"
+		}
+		return eventstore.OpenSQLiteStore(path)
+	default:
+		return eventstore.NewMemoryStore(100), nil
+	}
 }
 
-// memoryStore is a simple in-memory ring buffer.
+// loadAccounts builds an auth.Accounts table from AUTH_USER/AUTH_PASS (a
+// single account granted the "write" scope). Deliberately fails closed: if
+// AUTH_USER isn't set, the table is empty and every protected route stays
+// unreachable until credentials are configured, rather than defaulting to
+// an open server.
 //
 // Português:
-// memoryStore é um buffer circular simples em memória.
-type memoryStore struct {
-	mu     sync.Mutex
-	events []Event
-	max    int
+// loadAccounts monta uma tabela auth.Accounts a partir de AUTH_USER/AUTH_PASS
+// (uma única conta com o escopo "write"). Falha fechado de propósito: se
+// AUTH_USER não estiver definido, a tabela fica vazia e toda rota protegida
+// permanece inacessível até que credenciais sejam configuradas, em vez de
+// assumir um servidor aberto por padrão.
+func loadAccounts() auth.Accounts {
+	user := os.Getenv("AUTH_USER")
+	if user == "" {
+		log.Printf("warning: AUTH_USER not set; /ingest, /git/* and write-side /nr/* are unreachable")
+		return auth.Accounts{}
+	}
+	return auth.Accounts{
+		user: {Password: os.Getenv("AUTH_PASS"), Scopes: []string{"write"}},
+	}
 }
 
-func newMemoryStore(max int) *memoryStore {
-	return &memoryStore{events: make([]Event, 0, max), max: max}
+// handleHealthz returns 200 OK.
+//
+// Português:
+// handleHealthz retorna 200 OK.
+func handleHealthz(c *middleware.Context) {
+	c.JSON(http.StatusOK, map[string]string{"status": "ok"})
 }
 
-func (s *memoryStore) add(ev Event) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	if len(s.events) == s.max {
-		copy(s.events, s.events[1:])
-		s.events[len(s.events)-1] = ev
-		return
-	}
-	s.events = append(s.events, ev)
-}
+// handleIngest receives arbitrary JSON and stores it. Method enforcement
+// and the body-size cap are handled upstream by the middleware chain.
+//
+// Português:
+// handleIngest recebe JSON arbitrário e armazena. A checagem de método e o
+// limite de tamanho do corpo são tratados a montante pela cadeia de middlewares.
+func handleIngest(store eventstore.EventStore) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		defer c.Request.Body.Close()
+
+		var raw json.RawMessage
+		if err := json.NewDecoder(c.Request.Body).Decode(&raw); err != nil {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid JSON: %v", err)})
+			return
+		}
 
-func (s *memoryStore) list() []Event {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-	out := make([]Event, len(s.events))
-	copy(out, s.events)
-	return out
+		ev := eventstore.NewEvent(fmt.Sprintf("%d", time.Now().UnixNano()), c.Request.RemoteAddr, raw)
+		stored, err := store.Add(ev)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, map[string]any{"status": "accepted", "id": stored.ID})
+	}
 }
 
-// writeJSON writes obj as JSON with code and CORS headers.
+// handleEvents returns stored events, optionally narrowed by the
+// ?since=<RFC3339>, ?limit=<n> and ?type=<value> (matched against the
+// "type" key of the event body) query parameters.
 //
 // Português:
-// writeJSON escreve obj como JSON com código e cabeçalhos CORS.
-func writeJSON(w http.ResponseWriter, code int, obj any) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-	w.Header().Set("Access-Control-Allow-Methods", "GET,POST,OPTIONS")
-	w.WriteHeader(code)
-	_ = json.NewEncoder(w).Encode(obj)
+// handleEvents retorna os eventos armazenados, opcionalmente restringidos
+// pelos parâmetros de consulta ?since=<RFC3339>, ?limit=<n> e
+// ?type=<valor> (comparado com a chave "type" do corpo do evento).
+func handleEvents(store eventstore.EventStore) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		q := c.Request.URL.Query()
+
+		filter := eventstore.Filter{TypePath: "type", TypeEquals: q.Get("type")}
+		if since := q.Get("since"); since != "" {
+			t, err := time.Parse(time.RFC3339, since)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid since: " + err.Error()})
+				return
+			}
+			filter.Since = t
+		}
+		if limit := q.Get("limit"); limit != "" {
+			n, err := strconv.Atoi(limit)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, map[string]string{"error": "invalid limit: " + err.Error()})
+				return
+			}
+			filter.Limit = n
+		}
+
+		events, err := store.List(filter)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, map[string]string{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, events)
+	}
 }
 
-// corsPreflight handles OPTIONS for CORS.
+// writeNodeRedBody relays a Node-RED admin API response body to the
+// browser as JSON, or a 502 with the underlying error.
 //
 // Português:
-// corsPreflight lida com OPTIONS para CORS.
-func corsPreflight(w http.ResponseWriter, r *http.Request) {
-	if r.Method == http.MethodOptions {
-		writeJSON(w, http.StatusNoContent, map[string]string{"ok": "true"})
+// writeNodeRedBody repassa o corpo de uma resposta da API admin do
+// Node-RED para o browser como JSON, ou um 502 com o erro subjacente.
+func writeNodeRedBody(c *middleware.Context, body []byte, err error) {
+	if err != nil {
+		c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
 		return
 	}
-	http.NotFound(w, r)
+	c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_, _ = c.Writer.Write(body)
 }
 
-// handleHealthz returns 200 OK.
+// handleNodeRedFlows proxies GET /flows to the browser.
 //
 // Português:
-// handleHealthz retorna 200 OK.
-func handleHealthz(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+// handleNodeRedFlows faz proxy de GET /flows para o browser.
+func handleNodeRedFlows(nr *nodered.Client) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		body, err := nr.GetFlows()
+		writeNodeRedBody(c, body, err)
+	}
 }
 
-// handleIngest receives arbitrary JSON and stores it.
+// handleNodeRedDeployFlows proxies POST /flows, forwarding the
+// Node-RED-Deployment-Type header (defaulting to "full").
 //
 // Português:
-// handleIngest recebe JSON arbitrário e armazena.
-func handleIngest(store *memoryStore) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodOptions {
-			corsPreflight(w, r)
-			return
-		}
-		if r.Method != http.MethodPost {
-			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
-			return
-		}
-		defer r.Body.Close()
-
-		// 1MB cap to avoid abuse in demo
-		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
-
-		var raw json.RawMessage
-		if err := json.NewDecoder(r.Body).Decode(&raw); err != nil {
-			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid JSON: %v", err)})
+// handleNodeRedDeployFlows faz proxy de POST /flows, repassando o
+// cabeçalho Node-RED-Deployment-Type (com "full" como padrão).
+func handleNodeRedDeployFlows(nr *nodered.Client) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		defer c.Request.Body.Close()
+		flows, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
 			return
 		}
-
-		ev := Event{
-			ID:         fmt.Sprintf("%d", time.Now().UnixNano()),
-			At:         time.Now(),
-			RemoteAddr: r.RemoteAddr,
-			Body:       raw,
+		deployType := nodered.DeploymentType(c.Request.Header.Get("Node-RED-Deployment-Type"))
+		if deployType == "" {
+			deployType = nodered.DeployFull
 		}
-		store.add(ev)
-		writeJSON(w, http.StatusAccepted, map[string]any{"status": "accepted", "id": ev.ID})
+		body, err := nr.DeployFlows(flows, deployType)
+		writeNodeRedBody(c, body, err)
 	}
 }
 
-// handleEvents returns all stored events.
+// handleNodeRedFlow proxies GET/PUT /flow/{id}.
 //
 // Português:
-// handleEvents retorna todos os eventos armazenados.
-func handleEvents(store *memoryStore) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		if r.Method == http.MethodOptions {
-			corsPreflight(w, r)
+// handleNodeRedFlow faz proxy de GET/PUT /flow/{id}.
+func handleNodeRedFlow(nr *nodered.Client) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		id := strings.TrimPrefix(c.Request.URL.Path, "/nr/flow/")
+		if id == "" {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": "flow id required"})
 			return
 		}
-		if r.Method != http.MethodGet {
-			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+
+		if c.Request.Method == http.MethodPut {
+			defer c.Request.Body.Close()
+			flow, err := io.ReadAll(c.Request.Body)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+				return
+			}
+			body, err := nr.PutFlow(id, flow)
+			writeNodeRedBody(c, body, err)
 			return
 		}
-		writeJSON(w, http.StatusOK, store.list())
+
+		body, err := nr.GetFlow(id)
+		writeNodeRedBody(c, body, err)
 	}
 }
 
-// fetchNodeRedFlows fetches /flows from Node-RED admin API.
+// handleNodeRedNodes proxies GET/POST /nodes.
 //
 // Português:
-// fetchNodeRedFlows busca /flows da API admin do Node-RED.
-func fetchNodeRedFlows(nodeRedBase string) ([]byte, int, error) {
-	url := nodeRedBase + "/flows"
-	req, _ := http.NewRequest(http.MethodGet, url, nil)
-	client := &http.Client{Timeout: 8 * time.Second}
+// handleNodeRedNodes faz proxy de GET/POST /nodes.
+func handleNodeRedNodes(nr *nodered.Client) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		if c.Request.Method == http.MethodPost {
+			defer c.Request.Body.Close()
+			var in struct {
+				Module  string `json:"module"`
+				Version string `json:"version"`
+			}
+			if err := json.NewDecoder(c.Request.Body).Decode(&in); err != nil {
+				c.JSON(http.StatusBadRequest, map[string]string{"error": "bad json"})
+				return
+			}
+			body, err := nr.InstallNode(in.Module, in.Version)
+			writeNodeRedBody(c, body, err)
+			return
+		}
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer resp.Body.Close()
-	body, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return body, resp.StatusCode, fmt.Errorf("node-red returned %d", resp.StatusCode)
+		body, err := nr.GetNodes()
+		writeNodeRedBody(c, body, err)
 	}
-	return body, resp.StatusCode, nil
 }
 
-// handleNodeRedFlows proxies Node-RED flows to the browser.
+// handleNodeRedDeleteNode proxies DELETE /nodes/{module}.
 //
 // Português:
-// handleNodeRedFlows faz proxy do JSON de flows do Node-RED para o browser.
-func handleNodeRedFlows(nodeRedBase string) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// CORS para permitir leitura de outros hosts se necessário
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		if r.Method == http.MethodOptions {
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-			w.Header().Set("Access-Control-Allow-Methods", "GET,OPTIONS")
-			w.WriteHeader(http.StatusNoContent)
-			return
-		}
-		if r.Method != http.MethodGet {
-			writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"error": "method not allowed"})
+// handleNodeRedDeleteNode faz proxy de DELETE /nodes/{module}.
+func handleNodeRedDeleteNode(nr *nodered.Client) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		module := strings.TrimPrefix(c.Request.URL.Path, "/nr/nodes/")
+		if module == "" {
+			c.JSON(http.StatusBadRequest, map[string]string{"error": "module required"})
 			return
 		}
-		body, _, err := fetchNodeRedFlows(nodeRedBase)
-		if err != nil {
-			writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error()})
+		if err := nr.DeleteNode(module); err != nil {
+			c.JSON(http.StatusBadGateway, map[string]string{"error": err.Error()})
 			return
 		}
-		w.Header().Set("Content-Type", "application/json; charset=utf-8")
-		_, _ = w.Write(body)
+		c.JSON(http.StatusOK, map[string]string{"status": "removed"})
+	}
+}
+
+// handleNodeRedSettings proxies GET /settings.
+//
+// Português:
+// handleNodeRedSettings faz proxy de GET /settings.
+func handleNodeRedSettings(nr *nodered.Client) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		body, err := nr.GetSettings()
+		writeNodeRedBody(c, body, err)
 	}
 }
 
@@ -520,6 +708,17 @@ var indexTPL = template.Must(template.New("index").Parse(`<!doctype html>
         <pre id="flowjson">loading…</pre>
       </details>
     </div>
+
+    <div class="card">
+      <h3>Git Clone</h3>
+      <div class="toolbar">
+        <input id="cloneRepo" placeholder="https://github.com/org/repo.git" style="flex:1;background:#0b0d11;color:var(--fg);border:1px solid var(--line);border-radius:8px;padding:8px">
+        <button id="cloneStart">Clone</button>
+        <button id="cloneCancel">Cancel</button>
+      </div>
+      <small>Live log prefers <code>/git/clone/ws/{id}</code>, falling back to <code>/git/clone/stream/{id}</code> (SSE) when WebSocket is unavailable.</small>
+      <pre id="cloneLog" style="margin-top:10px">idle</pre>
+    </div>
   </div>
 
 <script>
@@ -531,13 +730,22 @@ async function postIngest() {
   await fetch(base + "/ingest", { method: "POST", headers: { "Content-Type": "application/json" }, body: JSON.stringify(obj) });
   await refreshEvents();
 }
+let liveEvents = [];
 async function refreshEvents() {
   const res = await fetch(base + "/events");
-  const arr = await res.json();
-  document.getElementById('events').textContent = JSON.stringify(arr, null, 2);
+  liveEvents = await res.json();
+  document.getElementById('events').textContent = JSON.stringify(liveEvents, null, 2);
 }
 document.getElementById('btn').addEventListener('click', postIngest);
-refreshEvents(); setInterval(refreshEvents, 2500);
+refreshEvents();
+
+// Push updates via SSE instead of polling; refetch on (re)connect and on
+// each new event so query-string filters set elsewhere stay honoured.
+const eventsSource = new EventSource(base + "/events/stream");
+eventsSource.onmessage = (ev) => {
+  const msg = JSON.parse(ev.data);
+  if (msg.type === "event" || msg.type === "hello") refreshEvents();
+};
 
 // ------- Flow viewer (simple canvas) -------
 async function loadFlow() {
@@ -619,6 +827,62 @@ function drawFlow(nodes, byId) {
 
 document.getElementById('refreshFlow').addEventListener('click', loadFlow);
 loadFlow();
+
+// ------- Git clone log (WS preferred, SSE fallback) -------
+let cloneNodeID = null;
+let cloneSocket = null;
+
+function appendCloneLog(line) {
+  const pre = document.getElementById('cloneLog');
+  pre.textContent = (pre.textContent === "idle" ? "" : pre.textContent) + line;
+}
+
+function onCloneFrame(raw) {
+  let msg; try { msg = JSON.parse(raw) } catch(e) { return }
+  if (msg.type === "log") appendCloneLog(msg.line);
+  else if (msg.type === "done") appendCloneLog("\n[done] code=" + msg.code + " target=" + msg.target + "\n");
+}
+
+function openCloneStreamSSE(nodeID, token) {
+  const es = new EventSource(base + "/git/clone/stream/" + nodeID + "?token=" + encodeURIComponent(token));
+  es.onmessage = (ev) => onCloneFrame(ev.data);
+  cloneSocket = es;
+}
+
+function openCloneStream(nodeID, token) {
+  if (!window.WebSocket) { openCloneStreamSSE(nodeID, token); return; }
+  const wsBase = base.replace(/^http/, "ws");
+  const ws = new WebSocket(wsBase + "/git/clone/ws/" + nodeID + "?token=" + encodeURIComponent(token));
+  ws.onmessage = (ev) => onCloneFrame(ev.data);
+  ws.onerror = () => { ws.close(); openCloneStreamSSE(nodeID, token); };
+  cloneSocket = ws;
+}
+
+async function startClone() {
+  const repo = document.getElementById('cloneRepo').value.trim();
+  if (!repo) { alert("repo required"); return; }
+  cloneNodeID = "clone-" + Date.now();
+  document.getElementById('cloneLog').textContent = "";
+  const res = await fetch(base + "/git/clone/start", {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify({ nodeId: cloneNodeID, repo: repo }),
+  });
+  const data = await res.json();
+  openCloneStream(cloneNodeID, data.streamToken);
+}
+
+function cancelClone() {
+  if (!cloneNodeID) return;
+  if (cloneSocket instanceof WebSocket && cloneSocket.readyState === WebSocket.OPEN) {
+    cloneSocket.send(JSON.stringify({ cmd: "cancel" }));
+    return;
+  }
+  fetch(base + "/git/clone/cancel/" + cloneNodeID, { method: "POST" });
+}
+
+document.getElementById('cloneStart').addEventListener('click', startClone);
+document.getElementById('cloneCancel').addEventListener('click', cancelClone);
 </script>
 </body>
 </html>`))
@@ -627,16 +891,16 @@ loadFlow();
 //
 // Português:
 // handleIndex serve a página HTML.
-func handleIndex(baseURL, nodeRedBase string) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+func handleIndex(baseURL, nodeRedBase string) middleware.HandlerFunc {
+	return func(c *middleware.Context) {
+		c.Writer.Header().Set("Content-Type", "text/html; charset=utf-8")
 		data := map[string]any{
 			"BaseURL":     baseURL,
 			"BaseURLJS":   template.JS(fmt.Sprintf("%q", baseURL)),
 			"NodeRedBase": nodeRedBase,
 			"Now":         time.Now().Format(time.RFC3339),
 		}
-		_ = indexTPL.Execute(w, data)
+		_ = indexTPL.Execute(c.Writer, data)
 	}
 }
 
@@ -656,20 +920,94 @@ func main() {
 	if nodeRedBase == "" {
 		nodeRedBase = "http://node-red:1880"
 	}
+	nrClient := nodered.New(nodeRedBase, os.Getenv("NODE_RED_USER"), os.Getenv("NODE_RED_PASS"))
+
+	store, err := openEventStore()
+	if err != nil {
+		log.Fatalf("fatal: opening event store: %v", err)
+	}
+
+	// base is shared by every route: panic recovery, request logging and
+	// CORS. Route-specific concerns (method, body size) are layered on top
+	// with base.Use so they don't leak into handlers that don't need them.
+	//
+	// Português:
+	// base é compartilhada por todas as rotas: recuperação de panic, log de
+	// requisição e CORS. Particularidades de cada rota (método, tamanho do
+	// corpo) são adicionadas com base.Use, sem vazar para handlers que não
+	// precisam delas.
+	base := middleware.New(middleware.Recovery(), middleware.RequestLogger(), middleware.CORS(middleware.CORSConfig{
+		AllowedMethods: []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"Content-Type", "Authorization"},
+	}))
+
+	// protected extends base with authentication and ACL enforcement; it is
+	// only used for routes that mutate state or touch the filesystem/network
+	// on the server's behalf (ingest, git clone, Node-RED writes). "/" and
+	// "/healthz" are built on base directly and stay public.
+	//
+	// Português:
+	// protected estende base com autenticação e aplicação da ACL; só é usada
+	// para rotas que alteram estado ou tocam o sistema de arquivos/rede em
+	// nome do servidor (ingest, clone git, escritas no Node-RED). "/" e
+	// "/healthz" são construídas direto sobre base e permanecem públicas.
+	acl, err := auth.LoadACL()
+	if err != nil {
+		log.Fatalf("fatal: loading ACL: %v", err)
+	}
+	accounts := loadAccounts()
+	authRealm := os.Getenv("AUTH_REALM")
+	protected := base.Use(auth.BasicAuth(accounts, authRealm), auth.RequireScopes(acl))
 
-	store := newMemoryStore(100)
+	h := loghub.NewHub()
+	eventsCtx, cancelEvents := context.WithCancel(context.Background())
+	defer cancelEvents()
+	go pumpEventStoreToHub(eventsCtx, store, h)
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/", handleIndex(baseURL, nodeRedBase))
-	mux.HandleFunc("/healthz", handleHealthz)
-	mux.HandleFunc("/ingest", handleIngest(store))
-	mux.HandleFunc("/events", handleEvents(store))
-	mux.HandleFunc("/nr/flows", handleNodeRedFlows(nodeRedBase)) // <<< viewer usa isto
-	mux.HandleFunc("/options", corsPreflight)
-
-	h := newSSEHub()
-	mux.HandleFunc("/git/clone/stream/", handleGitStream(h))
-	mux.HandleFunc("/git/clone/start", handleGitStart(h))
+	mux.HandleFunc("/", base.Use(middleware.RequireMethod(http.MethodGet)).Then(handleIndex(baseURL, nodeRedBase)))
+	mux.HandleFunc("/healthz", base.Then(handleHealthz))
+	mux.HandleFunc("/ingest", protected.Use(middleware.RequireMethod(http.MethodPost), middleware.MaxBody(1<<20), auth.Audit("ingest")).Then(handleIngest(store)))
+	mux.HandleFunc("/events", base.Use(middleware.RequireMethod(http.MethodGet)).Then(handleEvents(store)))
+	mux.HandleFunc("/events/stream", base.Use(middleware.RequireMethod(http.MethodGet)).Then(handleEventsStream(h)))
+	getFlows := base.Use(middleware.RequireMethod(http.MethodGet)).Then(handleNodeRedFlows(nrClient))
+	postFlows := protected.Use(middleware.RequireMethod(http.MethodPost), auth.Audit("nodered.deploy")).Then(handleNodeRedDeployFlows(nrClient))
+	mux.HandleFunc("/nr/flows", func(w http.ResponseWriter, r *http.Request) { // <<< viewer usa isto (GET, público)
+		if r.Method == http.MethodPost {
+			postFlows(w, r)
+			return
+		}
+		getFlows(w, r)
+	})
+
+	getFlow := base.Use(middleware.RequireMethod(http.MethodGet)).Then(handleNodeRedFlow(nrClient))
+	putFlow := protected.Use(middleware.RequireMethod(http.MethodPut), auth.Audit("nodered.flow")).Then(handleNodeRedFlow(nrClient))
+	mux.HandleFunc("/nr/flow/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			putFlow(w, r)
+			return
+		}
+		getFlow(w, r)
+	})
+
+	getNodes := base.Use(middleware.RequireMethod(http.MethodGet)).Then(handleNodeRedNodes(nrClient))
+	postNodes := protected.Use(middleware.RequireMethod(http.MethodPost), auth.Audit("nodered.nodes")).Then(handleNodeRedNodes(nrClient))
+	mux.HandleFunc("/nr/nodes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			postNodes(w, r)
+			return
+		}
+		getNodes(w, r)
+	})
+	mux.HandleFunc("/nr/nodes/", protected.Use(middleware.RequireMethod(http.MethodDelete), auth.Audit("nodered.nodes.delete")).Then(handleNodeRedDeleteNode(nrClient)))
+	mux.HandleFunc("/nr/settings", base.Use(middleware.RequireMethod(http.MethodGet)).Then(handleNodeRedSettings(nrClient)))
+	mux.HandleFunc("/options", base.Then(func(c *middleware.Context) { c.JSON(http.StatusNoContent, map[string]string{"ok": "true"}) }))
+
+	gitMgr := gitclone.NewManager()
+	mux.HandleFunc("/git/clone/stream/", base.Use(middleware.RequireMethod(http.MethodGet)).Then(handleGitStream(h)))
+	mux.HandleFunc("/git/clone/ws/", base.Use(middleware.RequireMethod(http.MethodGet)).Then(handleGitWS(h, gitMgr)))
+	mux.HandleFunc("/git/clone/start", protected.Use(middleware.RequireMethod(http.MethodPost), auth.Audit("git.start")).Then(handleGitStart(h, gitMgr)))
+	mux.HandleFunc("/git/clone/cancel/", protected.Use(middleware.RequireMethod(http.MethodPost, http.MethodDelete), auth.Audit("git.cancel")).Then(handleGitCancel(gitMgr)))
 
 	addr := ":" + port
 	log.Printf("server listening on %s (Node-RED at %s)", addr, nodeRedBase)
@@ -0,0 +1,182 @@
+// Package middleware
+//
+// English:
+//
+//	Small HTTP middleware chain modeled on the Gin Context/Next/Abort
+//	pattern. It exists to stop every handler in this codebase from
+//	re-implementing CORS headers, method checks, and JSON error responses:
+//	a Chain composes reusable HandlerFuncs and a Context threads shared
+//	state (the request id, an auth Principal, etc.) between them.
+//
+// Português:
+//
+//	Cadeia de middlewares HTTP pequena, inspirada no padrão
+//	Context/Next/Abort do Gin. Existe para que os handlers deste repositório
+//	parem de reimplementar cabeçalhos CORS, checagem de método e respostas
+//	de erro JSON: uma Chain compõe HandlerFuncs reutilizáveis e um Context
+//	encaminha estado compartilhado (o id da requisição, um Principal de
+//	auth etc.) entre eles.
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+)
+
+// abortIndex is set on Context.index by Abort so Next never resumes.
+//
+// Português:
+// abortIndex é atribuído a Context.index por Abort para que Next nunca retome.
+const abortIndex = 1 << 30
+
+// HandlerFunc is one link in a Chain.
+//
+// Português:
+// HandlerFunc é um elo de uma Chain.
+type HandlerFunc func(*Context)
+
+// Context carries the request/response pair plus cross-handler state
+// through a Chain. It is not safe for concurrent use by multiple
+// goroutines, except through its Keys accessors which are locked.
+//
+// Português:
+// Context carrega o par request/response e o estado compartilhado entre
+// handlers ao longo de uma Chain. Não é seguro para uso concorrente por
+// múltiplas goroutines, exceto pelos acessores de Keys, que são protegidos.
+type Context struct {
+	Writer  http.ResponseWriter
+	Request *http.Request
+
+	handlers []HandlerFunc
+	index    int
+
+	keysMu sync.RWMutex
+	keys   map[string]any
+}
+
+// Next executes the remaining handlers in the chain. Calling it from a
+// middleware runs everything "downstream" before the middleware's own code
+// after the call; a middleware that returns without calling it does not
+// stop the chain, since the loop here goes on to the next handler
+// (mirroring Gin). Only Abort stops the pending handlers.
+//
+// Português:
+// Next executa os handlers restantes da cadeia. Chamá-lo a partir de um
+// middleware roda tudo "abaixo" antes do código do próprio middleware
+// após a chamada; um middleware que retorna sem chamá-lo não interrompe a
+// cadeia, pois o loop aqui segue para o próximo handler (espelhando o
+// Gin). Só Abort impede os handlers pendentes.
+func (c *Context) Next() {
+	c.index++
+	for c.index < len(c.handlers) {
+		c.handlers[c.index](c)
+		c.index++
+	}
+}
+
+// Abort prevents pending handlers from being called. It does not stop the
+// current handler; the caller should return right after calling Abort.
+//
+// Português:
+// Abort impede que os handlers pendentes sejam chamados. Não interrompe o
+// handler atual; o chamador deve retornar logo após chamar Abort.
+func (c *Context) Abort() {
+	c.index = abortIndex
+}
+
+// IsAborted reports whether Abort was called.
+//
+// Português:
+// IsAborted informa se Abort foi chamado.
+func (c *Context) IsAborted() bool {
+	return c.index >= abortIndex
+}
+
+// AbortWithJSON aborts the chain and writes obj as the JSON response.
+//
+// Português:
+// AbortWithJSON aborta a cadeia e escreve obj como resposta JSON.
+func (c *Context) AbortWithJSON(code int, obj any) {
+	c.JSON(code, obj)
+	c.Abort()
+}
+
+// JSON writes obj as JSON with the given status code.
+//
+// Português:
+// JSON escreve obj como JSON com o código de status informado.
+func (c *Context) JSON(code int, obj any) {
+	c.Writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	c.Writer.WriteHeader(code)
+	_ = json.NewEncoder(c.Writer).Encode(obj)
+}
+
+// Set stores a value under key for the lifetime of this request.
+//
+// Português:
+// Set guarda um valor sob key durante a vida desta requisição.
+func (c *Context) Set(key string, value any) {
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+	if c.keys == nil {
+		c.keys = make(map[string]any)
+	}
+	c.keys[key] = value
+}
+
+// Get retrieves the value stored under key, if any.
+//
+// Português:
+// Get recupera o valor guardado sob key, se houver.
+func (c *Context) Get(key string) (any, bool) {
+	c.keysMu.RLock()
+	defer c.keysMu.RUnlock()
+	v, ok := c.keys[key]
+	return v, ok
+}
+
+// Chain is an ordered list of middlewares terminated by a final handler.
+//
+// Português:
+// Chain é uma lista ordenada de middlewares terminada por um handler final.
+type Chain struct {
+	middlewares []HandlerFunc
+}
+
+// New creates a Chain starting with the given middlewares.
+//
+// Português:
+// New cria uma Chain começando com os middlewares informados.
+func New(middlewares ...HandlerFunc) *Chain {
+	return &Chain{middlewares: append([]HandlerFunc(nil), middlewares...)}
+}
+
+// Use returns a new Chain with middlewares appended after ch's own. ch is
+// left untouched, so the same base Chain can be reused to build several
+// route-specific chains without them clobbering each other.
+//
+// Português:
+// Use retorna uma nova Chain com os middlewares anexados após os de ch. ch
+// permanece intocada, de forma que a mesma Chain base possa ser reutilizada
+// para montar várias cadeias específicas de rota sem que uma atropele a outra.
+func (ch *Chain) Use(middlewares ...HandlerFunc) *Chain {
+	combined := make([]HandlerFunc, 0, len(ch.middlewares)+len(middlewares))
+	combined = append(combined, ch.middlewares...)
+	combined = append(combined, middlewares...)
+	return &Chain{middlewares: combined}
+}
+
+// Then terminates the chain with final and returns a plain http.HandlerFunc
+// ready to register on an http.ServeMux.
+//
+// Português:
+// Then termina a cadeia com final e retorna um http.HandlerFunc simples,
+// pronto para ser registrado em um http.ServeMux.
+func (ch *Chain) Then(final HandlerFunc) http.HandlerFunc {
+	handlers := append(append([]HandlerFunc(nil), ch.middlewares...), final)
+	return func(w http.ResponseWriter, r *http.Request) {
+		c := &Context{Writer: w, Request: r, handlers: handlers, index: -1}
+		c.Next()
+	}
+}
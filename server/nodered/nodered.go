@@ -0,0 +1,301 @@
+// Package nodered
+//
+// English:
+//
+//	Typed client for the Node-RED admin HTTP API. It exists so other Go
+//	packages (not only the browser through the /nr/* proxy) can talk to
+//	Node-RED directly: deploy flows, manage installed nodes, and fetch
+//	settings. It also owns bearer-token acquisition against /auth/token and
+//	transparently re-authenticates on a 401.
+//
+// Português:
+//
+//	Cliente tipado para a API HTTP de administração do Node-RED. Existe
+//	para que outros pacotes Go (não só o browser via proxy /nr/*) possam
+//	falar com o Node-RED diretamente: fazer deploy de flows, gerenciar nodes
+//	instalados e buscar configurações. Também cuida da obtenção do bearer
+//	token em /auth/token e reautentica de forma transparente em um 401.
+package nodered
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// DeploymentType mirrors the values Node-RED accepts in the
+// Node-RED-Deployment-Type header when deploying flows.
+//
+// Português:
+// DeploymentType espelha os valores que o Node-RED aceita no cabeçalho
+// Node-RED-Deployment-Type ao fazer deploy de flows.
+type DeploymentType string
+
+const (
+	DeployFull  DeploymentType = "full"
+	DeployNodes DeploymentType = "nodes"
+	DeployFlows DeploymentType = "flows"
+)
+
+// Client talks to a single Node-RED instance's admin API.
+//
+// Português:
+// Client fala com a API admin de uma única instância do Node-RED.
+type Client struct {
+	baseURL    string
+	user, pass string
+	httpClient *http.Client
+
+	tokenMu sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// New creates a Client for baseURL (e.g. "http://node-red:1880"). user and
+// pass may be empty when the instance has no adminAuth configured.
+//
+// Português:
+// New cria um Client para baseURL (ex.: "http://node-red:1880"). user e
+// pass podem ser vazios quando a instância não tem adminAuth configurado.
+func New(baseURL, user, pass string) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		user:       user,
+		pass:       pass,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// tokenResponse mirrors Node-RED's POST /auth/token payload.
+//
+// Português:
+// tokenResponse espelha o payload de POST /auth/token do Node-RED.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+}
+
+// authenticate obtains a fresh bearer token and caches it until the expiry
+// time Node-RED reported for it.
+//
+// Português:
+// authenticate obtém um bearer token novo e o armazena em cache até o
+// horário de expiração informado pelo Node-RED.
+func (c *Client) authenticate() (string, error) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.token != "" && time.Now().Before(c.expires) {
+		return c.token, nil
+	}
+	if c.user == "" {
+		return "", nil // instance has no auth configured
+	}
+
+	form := url.Values{
+		"client_id":  {"node-red-admin"},
+		"grant_type": {"password"},
+		"scope":      {"*"},
+		"username":   {c.user},
+		"password":   {c.pass},
+	}
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/auth/token", bytes.NewBufferString(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("nodered auth: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("nodered auth returned %d", resp.StatusCode)
+	}
+
+	var tok tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return "", fmt.Errorf("nodered auth: decode: %w", err)
+	}
+
+	c.token = tok.AccessToken
+	c.expires = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return c.token, nil
+}
+
+// do performs req against Node-RED, attaching a bearer token and retrying
+// once with a fresh token if the first attempt is rejected with 401.
+//
+// Português:
+// do executa req contra o Node-RED, anexando um bearer token e tentando
+// novamente uma vez com um token novo se a primeira tentativa for
+// rejeitada com 401.
+func (c *Client) do(method, urlPath string, headers map[string]string, body []byte) ([]byte, int, error) {
+	send := func(token string) (*http.Response, error) {
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequest(method, c.baseURL+urlPath, reader)
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		return c.httpClient.Do(req)
+	}
+
+	token, err := c.authenticate()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := send(token)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		c.tokenMu.Lock()
+		c.token = ""
+		c.tokenMu.Unlock()
+
+		token, err = c.authenticate()
+		if err != nil {
+			return nil, 0, err
+		}
+		resp2, err := send(token)
+		if err != nil {
+			return nil, 0, err
+		}
+		defer resp2.Body.Close()
+		respBody, _ := io.ReadAll(resp2.Body)
+		return respBody, resp2.StatusCode, nil
+	}
+
+	respBody, _ := io.ReadAll(resp.Body)
+	return respBody, resp.StatusCode, nil
+}
+
+// GetFlows returns the full flow configuration.
+//
+// Português:
+// GetFlows retorna a configuração completa de flows.
+func (c *Client) GetFlows() ([]byte, error) {
+	return c.getOK(http.MethodGet, "/flows", nil)
+}
+
+// DeployFlows uploads a new flow configuration with the given deployment
+// type ("full", "nodes" or "flows").
+//
+// Português:
+// DeployFlows envia uma nova configuração de flows com o tipo de deploy
+// informado ("full", "nodes" ou "flows").
+func (c *Client) DeployFlows(flows []byte, deployType DeploymentType) ([]byte, error) {
+	headers := map[string]string{
+		"Content-Type":             "application/json",
+		"Node-RED-Deployment-Type": string(deployType),
+	}
+	body, status, err := c.do(http.MethodPost, "/flows", headers, flows)
+	if err != nil {
+		return nil, err
+	}
+	return okOrErr(body, status)
+}
+
+// GetFlow returns a single flow (tab) by id.
+//
+// Português:
+// GetFlow retorna um único flow (tab) por id.
+func (c *Client) GetFlow(id string) ([]byte, error) {
+	return c.getOK(http.MethodGet, "/flow/"+id, nil)
+}
+
+// PutFlow replaces a single flow (tab) by id.
+//
+// Português:
+// PutFlow substitui um único flow (tab) por id.
+func (c *Client) PutFlow(id string, flow []byte) ([]byte, error) {
+	headers := map[string]string{"Content-Type": "application/json"}
+	body, status, err := c.do(http.MethodPut, "/flow/"+id, headers, flow)
+	if err != nil {
+		return nil, err
+	}
+	return okOrErr(body, status)
+}
+
+// GetNodes lists installed node modules.
+//
+// Português:
+// GetNodes lista os módulos de node instalados.
+func (c *Client) GetNodes() ([]byte, error) {
+	return c.getOK(http.MethodGet, "/nodes", nil)
+}
+
+// InstallNode installs a node module by name, optionally pinning version.
+//
+// Português:
+// InstallNode instala um módulo de node por nome, opcionalmente fixando a versão.
+func (c *Client) InstallNode(module, version string) ([]byte, error) {
+	payload := map[string]string{"module": module}
+	if version != "" {
+		payload["version"] = version
+	}
+	body, _ := json.Marshal(payload)
+	respBody, status, err := c.do(http.MethodPost, "/nodes", map[string]string{"Content-Type": "application/json"}, body)
+	if err != nil {
+		return nil, err
+	}
+	return okOrErr(respBody, status)
+}
+
+// DeleteNode removes an installed node module.
+//
+// Português:
+// DeleteNode remove um módulo de node instalado.
+func (c *Client) DeleteNode(module string) error {
+	_, status, err := c.do(http.MethodDelete, "/nodes/"+module, nil, nil)
+	if err != nil {
+		return err
+	}
+	if status/100 != 2 {
+		return fmt.Errorf("nodered returned %d", status)
+	}
+	return nil
+}
+
+// GetSettings returns the runtime settings exposed by Node-RED.
+//
+// Português:
+// GetSettings retorna as configurações de runtime expostas pelo Node-RED.
+func (c *Client) GetSettings() ([]byte, error) {
+	return c.getOK(http.MethodGet, "/settings", nil)
+}
+
+func (c *Client) getOK(method, urlPath string, headers map[string]string) ([]byte, error) {
+	body, status, err := c.do(method, urlPath, headers, nil)
+	if err != nil {
+		return nil, err
+	}
+	return okOrErr(body, status)
+}
+
+func okOrErr(body []byte, status int) ([]byte, error) {
+	if status/100 != 2 {
+		return body, fmt.Errorf("nodered returned %d", status)
+	}
+	return body, nil
+}
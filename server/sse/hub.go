@@ -0,0 +1,141 @@
+// Package sse
+//
+// English:
+//
+//	Server-Sent Events adapter for loghub: it implements loghub.Transport
+//	by writing SSE frames to an http.ResponseWriter and exposes Open, which
+//	upgrades a request into a subscribed, backpressure-safe connection.
+//
+// Português:
+//
+//	Adaptador de Server-Sent Events para o loghub: implementa
+//	loghub.Transport escrevendo frames SSE em um http.ResponseWriter e
+//	expõe Open, que transforma uma requisição em uma conexão assinante,
+//	segura quanto a backpressure.
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/helmutkemper/recode/server/loghub"
+)
+
+// transport implements loghub.Transport on top of an http.ResponseWriter.
+//
+// Português:
+// transport implementa loghub.Transport sobre um http.ResponseWriter.
+type transport struct {
+	w  http.ResponseWriter
+	fl http.Flusher
+}
+
+func (t *transport) WriteFrame(msg loghub.Msg) error {
+	event := msg.Event
+	if event == "" {
+		event = "message"
+	}
+
+	var payload []byte
+	switch v := msg.Data.(type) {
+	case []byte:
+		payload = v
+	case string:
+		payload = []byte(v)
+	default:
+		payload, _ = json.Marshal(v)
+	}
+
+	var frame string
+	if event == "message" {
+		frame = fmt.Sprintf("data: %s\n\n", payload)
+	} else {
+		frame = fmt.Sprintf("event: %s\ndata: %s\n\n", event, payload)
+	}
+
+	if _, err := t.w.Write([]byte(frame)); err != nil {
+		return err
+	}
+	t.fl.Flush()
+	return nil
+}
+
+// Open upgrades w/r into an SSE connection subscribed to id on h, writes
+// the initial hello (preceded by a protocol-level `retry:` hint so browsers
+// reconnect promptly), and blocks sending keepalive pings until r's context
+// is done. It returns once the client disconnects.
+//
+// Português:
+// Open transforma w/r em uma conexão SSE assinante de id em h, escreve o
+// hello inicial (precedido por uma dica `retry:` em nível de protocolo para
+// que browsers reconectem rapidamente), e bloqueia enviando pings de
+// keepalive até o contexto de r terminar. Retorna quando o cliente desconecta.
+func Open(h *loghub.Hub, id string, w http.ResponseWriter, r *http.Request) error {
+	fl, ok := w.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("sse: streaming unsupported")
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	writeDeadline, idleTimeout := h.Settings()
+
+	// SSE has no real inbound reads to reset a read deadline on; a dead
+	// client is instead detected through r's context being cancelled when
+	// the underlying connection drops, handled in the select loop below.
+	// ReadDeadline is therefore left unset here (see wsgit.Open for a
+	// transport that does enforce it, since WebSocket has real reads).
+	//
+	// Português:
+	// SSE não tem leituras de entrada reais para reiniciar um deadline de
+	// leitura; um cliente morto é detectado através do cancelamento do
+	// contexto de r quando a conexão subjacente cai, tratado no loop de
+	// select abaixo. Por isso ReadDeadline fica sem uso aqui (veja
+	// wsgit.Open para um transporte que de fato o aplica, já que WebSocket
+	// tem leituras reais).
+	c := loghub.NewClient(&transport{w: w, fl: fl})
+	c.WriteDeadline.Set(time.Now().Add(writeDeadline))
+
+	h.Add(id, c)
+	defer h.Remove(id, c)
+
+	// retry: is a protocol-level SSE field, not JSON payload; browsers use it
+	// to pick their reconnect delay after the connection drops.
+	//
+	// Português:
+	// retry: é um campo do protocolo SSE, não payload JSON; browsers o usam
+	// para escolher o atraso de reconexão após a conexão cair.
+	fmt.Fprintf(w, "retry: %d\n", idleTimeout.Milliseconds()/2)
+	fl.Flush()
+
+	go c.RunWriter()
+
+	if !c.Send(loghub.Msg{Data: `{"type":"hello"}`}) {
+		c.Close()
+		return nil
+	}
+
+	ping := time.NewTicker(idleTimeout / 2)
+	defer ping.Stop()
+
+	ctx := r.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			c.Close()
+			return nil
+		case <-c.Done():
+			return nil
+		case <-ping.C:
+			c.WriteDeadline.Set(time.Now().Add(writeDeadline))
+			if !c.Send(loghub.Msg{Event: "ping", Data: "{}"}) {
+				c.Close()
+				return nil
+			}
+		}
+	}
+}
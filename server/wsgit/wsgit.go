@@ -0,0 +1,172 @@
+// Package wsgit
+//
+// English:
+//
+//	WebSocket adapter for loghub: unlike sse, a WebSocket connection is
+//	bidirectional, so besides implementing loghub.Transport to push Msg
+//	values outbound, Open also reads inbound control frames ({"cmd":"cancel"},
+//	"pause", "resume", "input") sent by the browser over the same socket.
+//	go-git's PlainCloneContext offers no hook to pause/resume a clone or to
+//	answer an interactive credential prompt mid-flight, so pause/resume/input
+//	are acknowledged as unsupported rather than faked.
+//
+// Português:
+//
+//	Adaptador WebSocket para o loghub: diferente do sse, uma conexão
+//	WebSocket é bidirecional, então além de implementar loghub.Transport
+//	para enviar valores Msg, Open também lê frames de controle de entrada
+//	({"cmd":"cancel"}, "pause", "resume", "input") enviados pelo browser no
+//	mesmo socket. O PlainCloneContext do go-git não oferece um gancho para
+//	pausar/retomar um clone ou responder um prompt interativo de credencial
+//	no meio do processo, então pause/resume/input são reconhecidos como não
+//	suportados em vez de simulados.
+package wsgit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+
+	"github.com/helmutkemper/recode/server/loghub"
+)
+
+// transport implements loghub.Transport on top of a websocket.Conn.
+//
+// Português:
+// transport implementa loghub.Transport sobre um websocket.Conn.
+type transport struct {
+	conn *websocket.Conn
+}
+
+func (t *transport) WriteFrame(msg loghub.Msg) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	switch v := msg.Data.(type) {
+	case []byte:
+		return t.conn.Write(ctx, websocket.MessageText, v)
+	case string:
+		return t.conn.Write(ctx, websocket.MessageText, []byte(v))
+	default:
+		return wsjson.Write(ctx, t.conn, v)
+	}
+}
+
+// controlFrame is a control message the browser may send inbound over the
+// same socket used for outbound log/event frames.
+//
+// Português:
+// controlFrame é uma mensagem de controle que o browser pode enviar na
+// direção de entrada, pelo mesmo socket usado para os frames de log/evento
+// de saída.
+type controlFrame struct {
+	Cmd  string `json:"cmd"`
+	Data string `json:"data,omitempty"`
+}
+
+// Open upgrades w/r to a WebSocket, subscribes it to id on h the same way
+// sse.Open does for SSE, and additionally reads inbound control frames
+// until the connection closes. onCancel is invoked for {"cmd":"cancel"};
+// pause/resume/input are answered with an "unsupported" error frame since
+// the underlying gitclone.Manager has no support for them.
+//
+// Português:
+// Open faz upgrade de w/r para WebSocket, assina id em h da mesma forma que
+// sse.Open faz para SSE, e adicionalmente lê frames de controle de entrada
+// até a conexão fechar. onCancel é chamado para {"cmd":"cancel"};
+// pause/resume/input são respondidos com um frame de erro "unsupported",
+// já que o gitclone.Manager subjacente não tem suporte para eles.
+func Open(h *loghub.Hub, id string, w http.ResponseWriter, r *http.Request, onCancel func() bool) error {
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return err
+	}
+	defer conn.CloseNow()
+
+	writeDeadline, idleTimeout := h.Settings()
+
+	c := loghub.NewClient(&transport{conn: conn})
+	c.WriteDeadline.Set(time.Now().Add(writeDeadline))
+	c.ReadDeadline.Set(time.Now().Add(idleTimeout))
+
+	h.Add(id, c)
+	defer h.Remove(id, c)
+
+	go c.RunWriter()
+
+	if !c.Send(loghub.Msg{Data: `{"type":"hello"}`}) {
+		c.Close()
+		return nil
+	}
+
+	ctx := r.Context()
+	reads := make(chan controlFrame)
+	readErrs := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			var cf controlFrame
+			if err := wsjson.Read(ctx, conn, &cf); err != nil {
+				readErrs <- err
+				return
+			}
+			select {
+			case reads <- cf:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(idleTimeout / 2)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			conn.Close(websocket.StatusNormalClosure, "context done")
+			c.Close()
+			return nil
+		case <-c.Done():
+			conn.Close(websocket.StatusNormalClosure, "closed")
+			return nil
+		case <-readErrs:
+			c.Close()
+			return nil
+		case <-c.ReadDeadline.Wait():
+			conn.Close(websocket.StatusPolicyViolation, "idle timeout")
+			c.Close()
+			return nil
+		case cf := <-reads:
+			c.ReadDeadline.Set(time.Now().Add(idleTimeout))
+			handleControl(c, cf, onCancel)
+		case <-ping.C:
+			c.WriteDeadline.Set(time.Now().Add(writeDeadline))
+			if !c.Send(loghub.Msg{Data: `{"type":"ping"}`}) {
+				c.Close()
+				return nil
+			}
+		}
+	}
+}
+
+// handleControl dispatches a single inbound control frame.
+//
+// Português:
+// handleControl despacha um único frame de controle de entrada.
+func handleControl(c *loghub.Client, cf controlFrame, onCancel func() bool) {
+	switch cf.Cmd {
+	case "cancel":
+		cancelled := onCancel != nil && onCancel()
+		c.Send(loghub.Msg{Data: fmt.Sprintf(`{"type":"cancelled","ok":%t}`, cancelled)})
+	case "pause", "resume", "input":
+		c.Send(loghub.Msg{Data: fmt.Sprintf(`{"type":"error","error":"unsupported command: %s"}`, cf.Cmd)})
+	default:
+		c.Send(loghub.Msg{Data: fmt.Sprintf(`{"type":"error","error":"unknown command: %s"}`, cf.Cmd)})
+	}
+}